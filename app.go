@@ -0,0 +1,258 @@
+package chi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// Hook 生命周期钩子
+// OnStart在服务开始监听前按注册顺序执行，OnStop在服务关闭后按注册的逆序执行
+type Hook struct {
+	// Name 钩子名称，用于错误信息
+	Name string
+	// OnStart 启动钩子，如连接数据库、启动调度器，可以为nil
+	OnStart func(ctx context.Context) error
+	// OnStop 停止钩子，如关闭数据库、缓存客户端、刷新日志，可以为nil
+	OnStop func(ctx context.Context) error
+}
+
+// AppConfig 应用生命周期配置
+type AppConfig struct {
+	// StartTimeout 所有启动钩子的总超时时间
+	StartTimeout time.Duration
+	// StopTimeout 关闭服务器和执行所有停止钩子的总超时时间，为0时使用服务器配置的关机超时
+	StopTimeout time.Duration
+	// Signals 触发关闭的系统信号
+	Signals []os.Signal
+}
+
+// DefaultAppConfig 默认应用生命周期配置
+var DefaultAppConfig = AppConfig{
+	StartTimeout: 15 * time.Second,
+	StopTimeout:  30 * time.Second,
+	Signals:      []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+}
+
+// App 应用生命周期管理
+// 负责启动钩子、HTTP服务、信号处理、优雅关机和停止钩子
+type App struct {
+	server *Server
+	config AppConfig
+
+	mu sync.Mutex
+	// hooks 已注册的钩子
+	hooks []Hook
+	// started 已成功执行启动钩子的数量，停止时只执行这些钩子的OnStop
+	started int
+	// done 调用Shutdown时关闭，用于主动触发关闭
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// =============================================================================
+// 构造函数
+// =============================================================================
+
+// NewApp 创建应用生命周期管理
+// 参数 server: 要运行的服务器
+// 参数 config: 可选的生命周期配置，默认使用DefaultAppConfig
+// 返回值: *App 应用实例
+func NewApp(server *Server, config ...AppConfig) *App {
+	cfg := DefaultAppConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = DefaultAppConfig.StartTimeout
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = server.cfg.Server.Timeout.Shutdown
+		if cfg.StopTimeout <= 0 {
+			cfg.StopTimeout = DefaultAppConfig.StopTimeout
+		}
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = DefaultAppConfig.Signals
+	}
+	return &App{
+		server: server,
+		config: cfg,
+		done:   make(chan struct{}),
+	}
+}
+
+// =============================================================================
+// 钩子注册
+// =============================================================================
+
+// Append 注册生命周期钩子
+// 参数 hook: 生命周期钩子
+func (a *App) Append(hook Hook) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hook)
+}
+
+// OnStart 注册启动钩子
+// 参数 name: 钩子名称
+// 参数 fn: 启动函数
+func (a *App) OnStart(name string, fn func(ctx context.Context) error) {
+	a.Append(Hook{Name: name, OnStart: fn})
+}
+
+// OnStop 注册停止钩子
+// 参数 name: 钩子名称
+// 参数 fn: 停止函数
+func (a *App) OnStop(name string, fn func(ctx context.Context) error) {
+	a.Append(Hook{Name: name, OnStop: fn})
+}
+
+// =============================================================================
+// 生命周期控制
+// =============================================================================
+
+// Start 按注册顺序执行启动钩子
+// 任一钩子失败时，按逆序执行已启动钩子的停止钩子并返回错误
+// 参数 ctx: 上下文，用于控制启动超时
+// 返回值: error 启动钩子的错误信息
+func (a *App) Start(ctx context.Context) error {
+	a.mu.Lock()
+	hooks := a.hooks
+	a.mu.Unlock()
+
+	for i, hook := range hooks {
+		if hook.OnStart != nil {
+			if err := runHook(ctx, hook.OnStart); err != nil {
+				err = fmt.Errorf("start hook %s: %w", hookName(hook, i), err)
+				stopCtx, cancel := context.WithTimeout(context.Background(), a.config.StopTimeout)
+				defer cancel()
+				return errors.Join(err, a.Stop(stopCtx))
+			}
+		}
+		a.mu.Lock()
+		a.started = i + 1
+		a.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop 按注册的逆序执行已启动钩子的停止钩子
+// 所有钩子都会执行，错误合并后返回
+// 参数 ctx: 上下文，用于控制停止超时
+// 返回值: error 停止钩子的错误信息
+func (a *App) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	hooks := a.hooks[:a.started]
+	a.started = 0
+	a.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].OnStop == nil {
+			continue
+		}
+		if err := runHook(ctx, hooks[i].OnStop); err != nil {
+			errs = append(errs, fmt.Errorf("stop hook %s: %w", hookName(hooks[i], i), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run 运行应用
+// 执行启动钩子后启动服务器的所有监听器（未调用Listen时按配置启动），阻塞直到收到关闭信号、调用Shutdown或服务出错，
+// 然后优雅关闭服务器并执行停止钩子
+// 返回值: error 启动钩子、服务、关机和停止钩子的错误信息
+func (a *App) Run() error {
+	startCtx, cancel := context.WithTimeout(context.Background(), a.config.StartTimeout)
+	err := a.Start(startCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	errCh, err := a.server.startListeners()
+	if err != nil {
+		stopCtx, cancel := context.WithTimeout(context.Background(), a.config.StopTimeout)
+		defer cancel()
+		return errors.Join(err, a.Stop(stopCtx))
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, a.config.Signals...)
+	defer signal.Stop(sigCh)
+	restartCh, stopRestart := a.server.watchRestart()
+	defer stopRestart()
+
+	var errs []error
+wait:
+	for {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("server error: %w", err))
+			}
+			break wait
+		case <-restartCh:
+			if a.server.restartOnSignal() {
+				break wait
+			}
+		case <-sigCh:
+			break wait
+		case <-a.done:
+			break wait
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.config.StopTimeout)
+	defer cancel()
+	if err := a.server.shutdown(stopCtx); err != nil {
+		errs = append(errs, fmt.Errorf("server shutdown: %w", err))
+	}
+	if err := a.Stop(stopCtx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown 主动触发关闭，Run会优雅关闭服务器并执行停止钩子后返回
+func (a *App) Shutdown() {
+	a.doneOnce.Do(func() {
+		close(a.done)
+	})
+}
+
+// Server 获取应用运行的服务器
+func (a *App) Server() *Server {
+	return a.server
+}
+
+// runHook 执行钩子，钩子未在上下文结束前返回时返回上下文错误
+func runHook(ctx context.Context, fn func(ctx context.Context) error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hookName 获取钩子名称，未命名时使用序号
+func hookName(hook Hook, index int) string {
+	if hook.Name != "" {
+		return hook.Name
+	}
+	return fmt.Sprintf("#%d", index)
+}
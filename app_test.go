@@ -0,0 +1,118 @@
+package chi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAppServer(t *testing.T, addr string) *Server {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Server.Mode = "test"
+	cfg.Server.Addr = addr
+	cfg.Server.Upload = t.TempDir()
+	s, err := NewWithConfig(cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func TestApp(t *testing.T) {
+	t.Run("HookOrder", func(t *testing.T) {
+		app := NewApp(newAppServer(t, "127.0.0.1:0"))
+		var calls []string
+		record := func(name string) func(context.Context) error {
+			return func(context.Context) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+		app.Append(Hook{Name: "db", OnStart: record("start db"), OnStop: record("stop db")})
+		app.Append(Hook{Name: "cache", OnStart: record("start cache"), OnStop: record("stop cache")})
+		app.OnStop("logger", record("sync logger"))
+		app.OnStart("ready", func(context.Context) error {
+			app.Shutdown()
+			return nil
+		})
+
+		require.NoError(t, app.Run())
+		assert.Equal(t, []string{"start db", "start cache", "sync logger", "stop cache", "stop db"}, calls)
+	})
+
+	t.Run("StartFailureRollsBack", func(t *testing.T) {
+		app := NewApp(newAppServer(t, "127.0.0.1:0"))
+		var stopped []string
+		app.Append(Hook{Name: "db", OnStart: func(context.Context) error { return nil }, OnStop: func(context.Context) error {
+			stopped = append(stopped, "db")
+			return nil
+		}})
+		app.Append(Hook{Name: "mongo", OnStart: func(context.Context) error { return errors.New("boom") }, OnStop: func(context.Context) error {
+			stopped = append(stopped, "mongo")
+			return nil
+		}})
+
+		err := app.Run()
+		assert.ErrorContains(t, err, "start hook mongo: boom")
+		assert.Equal(t, []string{"db"}, stopped)
+	})
+
+	t.Run("HookTimeout", func(t *testing.T) {
+		app := NewApp(newAppServer(t, "127.0.0.1:0"), AppConfig{StartTimeout: 20 * time.Millisecond})
+		app.OnStart("slow", func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+		assert.ErrorIs(t, app.Run(), context.DeadlineExceeded)
+	})
+
+	t.Run("ServerErrorSurfaced", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		app := NewApp(newAppServer(t, ln.Addr().String()))
+		stopped := false
+		app.OnStop("db", func(context.Context) error {
+			stopped = true
+			return nil
+		})
+		err = app.Run()
+		assert.ErrorContains(t, err, "failed to listen")
+		assert.True(t, stopped)
+	})
+}
+
+func TestRunUnixShutdown(t *testing.T) {
+	s := newTestServer()
+	s.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+
+	socket := filepath.Join(t.TempDir(), "chi.sock")
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.RunUnix(socket) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://unix/ping")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.shutdown(ctx))
+	assert.NoError(t, <-errCh)
+}
@@ -0,0 +1,84 @@
+package chi
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =============================================================================
+// 路由授权
+// =============================================================================
+
+// permissionsMetaKey 路由要求的权限在路由元数据中的键
+const permissionsMetaKey = "permissions"
+
+// Authorizer 授权器
+// 判断当前用户是否拥有路由要求的权限，见Require和Server.SetAuthorizer
+type Authorizer interface {
+	// Authorize 检查当前请求是否拥有所有权限
+	// 返回nil表示允许；返回*Error时按该错误响应（如ErrUnauthorized），其他错误按ErrForbidden响应
+	Authorize(c *Context, permissions []string) error
+}
+
+// AuthorizerFunc 函数形式的授权器
+type AuthorizerFunc func(c *Context, permissions []string) error
+
+// Authorize 实现Authorizer接口
+func (f AuthorizerFunc) Authorize(c *Context, permissions []string) error {
+	return f(c, permissions)
+}
+
+// SetAuthorizer 设置服务器的授权器，Require声明的权限由它检查
+// 参数 a: 授权器，为nil时所有带Require的路由都返回ErrForbidden
+func (s *Server) SetAuthorizer(a Authorizer) {
+	s.authorizer = a
+}
+
+// Require 声明路由需要的权限，如Require("order:read")
+// 权限写入路由元数据，请求到达处理函数前由Server.SetAuthorizer设置的授权器检查，
+// 未通过时通过Res返回ErrForbidden（或授权器返回的*Error）并终止处理链。
+// 认证中间件应注册为全局或路由组中间件，以便在授权之前执行
+// 参数 permissions: 需要同时拥有的权限
+func Require(permissions ...string) RouteOption {
+	return func(cfg *routeConfig) {
+		if cfg.meta == nil {
+			cfg.meta = make(map[string]any)
+		}
+		existing, _ := cfg.meta[permissionsMetaKey].([]string)
+		required := append(append([]string(nil), existing...), permissions...)
+		cfg.meta[permissionsMetaKey] = required
+		if len(existing) > 0 {
+			// 检查中间件已经添加，元数据在请求时读取
+			return
+		}
+		cfg.middleware = append(cfg.middleware, authorize)
+	}
+}
+
+// RequiredPermissions 获取当前路由通过Require声明的权限
+// 返回值: []string 权限列表，未声明时为nil
+func (c *Context) RequiredPermissions() []string {
+	v, _ := c.RouteMeta(permissionsMetaKey)
+	permissions, _ := v.([]string)
+	return permissions
+}
+
+// authorize 检查路由要求的权限
+func authorize(ginCtx *gin.Context) {
+	c := getContext(ginCtx)
+	var err error
+	s := c.server()
+	if s == nil || s.authorizer == nil {
+		err = ErrForbidden.WithMessage("未配置授权器")
+	} else if err = s.authorizer.Authorize(c, c.RequiredPermissions()); err == nil {
+		return
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		err = ErrForbidden.Wrap(err)
+	}
+	Res(c, err)
+	c.Abort()
+}
@@ -0,0 +1,57 @@
+package chi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequire(t *testing.T) {
+	s := newTestServer()
+	s.UseHTTPStatus(true)
+
+	// 未配置授权器时拒绝访问
+	s.GET("/orders", func(c *Context) { c.Status(http.StatusOK) }, Require("order:read"))
+	assert.Equal(t, http.StatusForbidden, doRequest(s, http.MethodGet, "/orders", "", nil).Code)
+
+	var checked []string
+	s.SetAuthorizer(AuthorizerFunc(func(c *Context, permissions []string) error {
+		checked = permissions
+		switch c.GetHeader("X-User") {
+		case "":
+			return ErrUnauthorized
+		case "admin":
+			return nil
+		}
+		return errors.New("missing permission " + strings.Join(permissions, ","))
+	}))
+
+	api := s.Group("/api")
+	api.DELETE("/orders/:id", func(c *Context) {
+		v, _ := c.RouteMeta("audit")
+		c.String(http.StatusOK, "%v %v", c.RequiredPermissions(), v)
+	}, Require("order:read"), Require("order:delete"), Meta("audit", true))
+	api.GET("/public", func(c *Context) {
+		assert.Nil(t, c.RequiredPermissions())
+		c.Status(http.StatusNoContent)
+	})
+
+	w := doRequest(s, http.MethodDelete, "/api/orders/1", "", map[string]string{"X-User": "admin"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[order:read order:delete] true", w.Body.String())
+	assert.Equal(t, []string{"order:read", "order:delete"}, checked)
+
+	w = doRequest(s, http.MethodDelete, "/api/orders/1", "", map[string]string{"X-User": "guest"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, ErrForbidden.Code, resp.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, doRequest(s, http.MethodDelete, "/api/orders/1", "", nil).Code)
+	assert.Equal(t, http.StatusNoContent, doRequest(s, http.MethodGet, "/api/public", "", nil).Code)
+}
@@ -2,9 +2,13 @@ package chi
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,11 +26,36 @@ type Server struct {
 	cfg *Config
 	// engine Gin 路由引擎，处理 HTTP 请求路由和中间件
 	engine *gin.Engine
-	// server 底层 HTTP 服务器实例，用于启动和管理服务
-	server *http.Server
+	// servers 已启动的底层服务器实例（HTTP/1.1、HTTP/2、HTTP/3），用于优雅关闭
+	servers []serverCloser
+	// listeners 通过Listen注册的监听器
+	listeners []*listener
+	// conns 连接状态统计和钩子
+	conns connTracker
+	// mu 保护servers和listeners，Run系列方法与Shutdown可能在不同goroutine中调用
+	mu sync.Mutex
 	// quit 退出信号通道，用于优雅关闭服务器
 	quit chan os.Signal
-}
+	// endpoints 类型化端点目录
+	endpoints *endpointCatalog
+	// useHTTPStatus 错误响应是否使用错误对应的HTTP状态码
+	useHTTPStatus bool
+	// renderer 响应渲染器，为nil时使用默认渲染器
+	renderer ResponseRenderer
+	// tls 根据配置创建的TLS证书管理器，未启用TLS时为nil
+	tls *TLSManager
+	// restart 平滑重启配置，未启用时为nil
+	restart *RestartConfig
+	// handedOff 监听器是否已通过平滑重启交给新进程
+	handedOff bool
+	// shutdownHooks 优雅关闭监听器前执行的函数
+	shutdownHooks []func(ctx context.Context)
+	// authorizer 检查Require声明的路由权限
+	authorizer Authorizer
+}
+
+// serverContextKey 在gin.Context中保存Server实例的键
+const serverContextKey = "chi.server"
 
 // HandlerFunc 处理函数类型定义
 // 封装了自定义的Context，提供更友好的API接口
@@ -44,11 +73,84 @@ type MiddlewareFunc func(*Context)
 // 初始化Gin引擎和退出信号通道，返回可用的服务器实例
 // 返回值: *Server 新创建的服务器实例
 func New() *Server {
+	initValidation()
 	engine := gin.New()
-	return &Server{
-		engine: engine,
-		quit:   make(chan os.Signal, 1),
+	s := &Server{
+		cfg:       DefaultConfig(),
+		engine:    engine,
+		quit:      make(chan os.Signal, 1),
+		endpoints: &endpointCatalog{},
+	}
+	// 将Server实例关联到每个请求，供Res等辅助函数读取服务器级配置，
+	// 并为请求分配处理链共享的Context
+	engine.Use(func(c *gin.Context) {
+		c.Set(serverContextKey, s)
+		ctx := acquireContext(c)
+		c.Next()
+		releaseContext(ctx)
+	})
+	return s
+}
+
+// NewWithConfig 根据配置创建Server实例
+// 按配置设置运行模式、可信代理、真实IP请求头、上传内存限制，并创建上传目录
+// 参数 cfg: 应用配置，为nil时使用默认配置
+// 返回值: *Server 新创建的服务器实例
+// 返回值: error 配置无效或应用配置失败时的错误信息
+func NewWithConfig(cfg *Config) (*Server, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if cfg.Server.Mode != "" {
+		gin.SetMode(cfg.Server.Mode)
 	}
+
+	s := New()
+	s.cfg = cfg
+
+	if len(cfg.Server.TrustedProxies) > 0 {
+		if err := s.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			return nil, fmt.Errorf("failed to set trusted proxies: %w", err)
+		}
+	}
+	if len(cfg.Server.RemoteIPHeaders) > 0 {
+		s.RemoteIPHeaders(cfg.Server.RemoteIPHeaders...)
+	}
+	s.MaxMultipartMemory(cfg.Server.MaxMultipartMemory)
+	s.UseHTTPStatus(cfg.Server.HTTPStatus)
+
+	if cfg.Server.TLS.Enabled {
+		tlsManager, err := NewTLSManager(cfg.Server.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure tls: %w", err)
+		}
+		s.tls = tlsManager
+	}
+
+	if cfg.Server.Upload != "" {
+		if err := os.MkdirAll(cfg.Server.Upload, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create upload directory: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+// NewFromFile 从配置文件创建Server实例
+// 等价于 LoadConfig 后调用 NewWithConfig
+// 参数 path: 配置文件路径
+// 返回值: *Server 新创建的服务器实例
+// 返回值: error 加载配置或创建服务器过程中的错误信息
+func NewFromFile(path string) (*Server, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithConfig(cfg)
 }
 
 // =============================================================================
@@ -126,6 +228,14 @@ func (s *Server) RedirectFixedPath(value bool) {
 	s.engine.RedirectFixedPath = value
 }
 
+// UseHTTPStatus 设置错误响应是否使用真实的HTTP状态码
+// 默认始终返回HTTP 200并通过响应体中的code区分错误，
+// 启用后Res使用错误对应的HTTP状态码，如ErrNotFound返回404
+// 参数 value: true表示使用真实状态码，false表示始终返回200
+func (s *Server) UseHTTPStatus(value bool) {
+	s.useHTTPStatus = value
+}
+
 // =============================================================================
 // 中间件管理
 // =============================================================================
@@ -161,64 +271,72 @@ func (s *Server) NoMethod(handler HandlerFunc) {
 // 用于处理数据查询和页面展示
 // 参数 path: 路由路径，支持参数如/users/:id
 // 参数 handler: 处理函数
-func (s *Server) GET(path string, handler HandlerFunc) {
-	s.engine.GET(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) GET(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.GET(path, routeHandlers(handler, opts)...)
 }
 
 // POST 注册POST请求路由
 // 用于处理数据创建和表单提交
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) POST(path string, handler HandlerFunc) {
-	s.engine.POST(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) POST(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.POST(path, routeHandlers(handler, opts)...)
 }
 
 // PUT 注册PUT请求路由
 // 用于处理数据的完整更新
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) PUT(path string, handler HandlerFunc) {
-	s.engine.PUT(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) PUT(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.PUT(path, routeHandlers(handler, opts)...)
 }
 
 // DELETE 注册DELETE请求路由
 // 用于处理数据删除操作
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) DELETE(path string, handler HandlerFunc) {
-	s.engine.DELETE(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) DELETE(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.DELETE(path, routeHandlers(handler, opts)...)
 }
 
 // PATCH 注册PATCH请求路由
 // 用于处理数据的部分更新
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) PATCH(path string, handler HandlerFunc) {
-	s.engine.PATCH(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) PATCH(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.PATCH(path, routeHandlers(handler, opts)...)
 }
 
 // OPTIONS 注册OPTIONS请求路由
 // 用于处理跨域预检请求和API选项查询
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) OPTIONS(path string, handler HandlerFunc) {
-	s.engine.OPTIONS(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) OPTIONS(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.OPTIONS(path, routeHandlers(handler, opts)...)
 }
 
 // HEAD 注册HEAD请求路由
 // 用于获取资源的元信息，不返回响应体
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) HEAD(path string, handler HandlerFunc) {
-	s.engine.HEAD(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) HEAD(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.HEAD(path, routeHandlers(handler, opts)...)
 }
 
 // Any 注册所有HTTP方法的路由
 // 该路由将响应所有HTTP方法的请求
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) Any(path string, handler HandlerFunc) {
-	s.engine.Any(path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) Any(path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.Any(path, routeHandlers(handler, opts)...)
 }
 
 // Match 注册指定HTTP方法列表的路由
@@ -226,8 +344,9 @@ func (s *Server) Any(path string, handler HandlerFunc) {
 // 参数 methods: HTTP方法列表，如["GET", "POST"]
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) Match(methods []string, path string, handler HandlerFunc) {
-	s.engine.Match(methods, path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) Match(methods []string, path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.Match(methods, path, routeHandlers(handler, opts)...)
 }
 
 // Handle 通用路由注册方法
@@ -235,8 +354,9 @@ func (s *Server) Match(methods []string, path string, handler HandlerFunc) {
 // 参数 httpMethod: HTTP方法名称，如"GET", "POST"
 // 参数 path: 路由路径
 // 参数 handler: 处理函数
-func (s *Server) Handle(httpMethod, path string, handler HandlerFunc) {
-	s.engine.Handle(httpMethod, path, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (s *Server) Handle(httpMethod, path string, handler HandlerFunc, opts ...RouteOption) {
+	s.engine.Handle(httpMethod, path, routeHandlers(handler, opts)...)
 }
 
 // Group 创建路由组
@@ -251,7 +371,8 @@ func (s *Server) Group(prefix string, middleware ...MiddlewareFunc) *RouterGroup
 		group.Use(wrapMiddleware(m))
 	}
 	return &RouterGroup{
-		group: group,
+		group:  group,
+		server: s,
 	}
 }
 
@@ -313,37 +434,106 @@ func (s *Server) SetFuncMap(funcMap map[string]interface{}) {
 // =============================================================================
 
 // Run 启动HTTP服务器
-// 在指定地址启动HTTP服务，默认为":8080"
+// 在指定地址启动HTTP服务，阻塞直到服务停止
+// 未指定地址时依次使用配置中的Server.Addr、环境变量PORT和":8080"
+// 可在其他goroutine中调用Shutdown或Stop停止服务，优雅关闭后返回nil
 // 参数 addr: 可选的监听地址，如":8080", "localhost:3000"
 // 返回值: error 启动过程中的错误信息
 func (s *Server) Run(addr ...string) error {
-	return s.engine.Run(addr...)
+	address := s.defaultAddr()
+	if len(addr) > 0 {
+		address = addr[0]
+	}
+	return serveError(s.trackServer(s.newHTTPServer(address)).ListenAndServe())
+}
+
+// defaultAddr 获取默认监听地址
+// 优先使用配置中的Server.Addr，其次为环境变量PORT，与gin的行为保持一致，最后为":8080"
+func (s *Server) defaultAddr() string {
+	if s.cfg != nil && s.cfg.Server.Addr != "" {
+		return s.cfg.Server.Addr
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
 }
 
 // RunTLS 启动HTTPS服务器
-// 使用TLS证书启动安全的HTTPS服务
+// 使用TLS证书启动安全的HTTPS服务，证书文件变化时自动重新加载，阻塞直到服务停止
 // 参数 addr: 监听地址
 // 参数 certFile: TLS证书文件路径
 // 参数 keyFile: TLS私钥文件路径
 // 返回值: error 启动过程中的错误信息
 func (s *Server) RunTLS(addr, certFile, keyFile string) error {
-	return s.engine.RunTLS(addr, certFile, keyFile)
+	tlsConfig, err := s.newFileTLSConfig(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	server := s.trackServer(s.newHTTPServer(addr))
+	server.TLSConfig = tlsConfig
+	return serveError(server.ListenAndServeTLS("", ""))
 }
 
 // RunUnix 启动Unix socket服务器
-// 在Unix域套接字上启动服务，适用于本地进程间通信
+// 在Unix域套接字上启动服务，适用于本地进程间通信，阻塞直到服务停止
 // 参数 file: Unix socket文件路径
 // 返回值: error 启动过程中的错误信息
 func (s *Server) RunUnix(file string) error {
-	return s.engine.RunUnix(file)
+	listener, err := net.Listen("unix", file)
+	if err != nil {
+		return fmt.Errorf("failed to listen on unix socket %s: %w", file, err)
+	}
+	defer os.Remove(file)
+	return serveError(s.trackServer(s.newHTTPServer("")).Serve(listener))
 }
 
 // RunFd 在指定文件描述符上启动服务器
-// 使用已存在的文件描述符启动服务，适用于特殊部署场景
+// 使用已存在的文件描述符启动服务，适用于特殊部署场景，阻塞直到服务停止
 // 参数 fd: 文件描述符
 // 返回值: error 启动过程中的错误信息
 func (s *Server) RunFd(fd int) error {
-	return s.engine.RunFd(fd)
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd@%d", fd))
+	defer f.Close()
+	listener, err := net.FileListener(f)
+	if err != nil {
+		return fmt.Errorf("failed to listen on fd %d: %w", fd, err)
+	}
+	return serveError(s.trackServer(s.newHTTPServer("")).Serve(listener))
+}
+
+// serverCloser 可优雅关闭的服务器，如http.Server和http3.Server
+type serverCloser interface {
+	Shutdown(ctx context.Context) error
+	Close() error
+}
+
+// trackServer 记录启动的HTTP服务器实例，供Shutdown和Stop使用
+func (s *Server) trackServer(server *http.Server) *http.Server {
+	s.track(server)
+	return server
+}
+
+// track 记录启动的服务器实例
+func (s *Server) track(server serverCloser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servers = append(s.servers, server)
+}
+
+// httpServers 获取已启动的服务器实例
+func (s *Server) httpServers() []serverCloser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]serverCloser(nil), s.servers...)
+}
+
+// serveError 转换服务返回的错误，优雅关闭导致的http.ErrServerClosed视为正常退出
+func serveError(err error) error {
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
 // =============================================================================
@@ -372,6 +562,36 @@ func (s *Server) Routes() gin.RoutesInfo {
 	return s.engine.Routes()
 }
 
+// BasePath 获取服务器的基础路径
+// 返回值: string 根路由组的路径前缀，通常为"/"
+func (s *Server) BasePath() string {
+	return s.engine.BasePath()
+}
+
+// Endpoints 获取所有类型化端点
+// 返回通过GETT、POSTT等类型化方法注册的端点及其请求、响应类型
+// 返回值: []Endpoint 端点列表
+func (s *Server) Endpoints() []Endpoint {
+	return s.endpoints.list()
+}
+
+// catalog 获取端点目录
+func (s *Server) catalog() *endpointCatalog {
+	return s.endpoints
+}
+
+// Config 获取服务器配置
+// 返回值: *Config 创建服务器时使用的配置
+func (s *Server) Config() *Config {
+	return s.cfg
+}
+
+// UploadDir 获取默认上传文件目录
+// 返回值: string 配置中的上传目录
+func (s *Server) UploadDir() string {
+	return s.cfg.Server.Upload
+}
+
 // =============================================================================
 // 优雅关机方法
 // =============================================================================
@@ -382,92 +602,161 @@ func (s *Server) Routes() gin.RoutesInfo {
 // 参数 timeout: 关闭超时时间，超过此时间将强制关闭
 // 返回值: error 关闭过程中的错误信息
 func (s *Server) Shutdown(timeout time.Duration) error {
-	// 监听系统信号
-	signal.Notify(s.quit, syscall.SIGINT, syscall.SIGTERM)
-	
-	// 等待信号
-	<-s.quit
-	
+	_ = s.waitSignal(nil)
+
 	// 创建带超时的上下文
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	
-	// 优雅关闭服务器
-	if s.server != nil {
-		return s.server.Shutdown(ctx)
-	}
-	return nil
+
+	return s.shutdown(ctx)
 }
 
 // RunWithGracefulShutdown 启动服务器并支持优雅关机
 // 在独立的goroutine中启动HTTP服务器，同时监听关闭信号
-// 当接收到关闭信号时，会优雅地关闭服务器
+// 当接收到关闭信号时，会优雅地关闭服务器；服务器启动失败时直接返回错误
 // 参数 addr: 监听地址，如":8080"
 // 参数 timeout: 关闭超时时间，默认30秒
 // 返回值: error 启动或关闭过程中的错误信息
 func (s *Server) RunWithGracefulShutdown(addr string, timeout ...time.Duration) error {
-	// 设置默认超时时间
-	shutdownTimeout := 30 * time.Second
-	if len(timeout) > 0 {
-		shutdownTimeout = timeout[0]
-	}
-	
-	// 创建HTTP服务器实例
-	s.server = &http.Server{
-		Addr:    addr,
-		Handler: s.engine,
-	}
-	
-	// 在goroutine中启动服务器
-	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			// 服务器启动失败，发送信号通知主goroutine
-			s.quit <- syscall.SIGTERM
-		}
-	}()
-	
-	// 优雅关机
-	return s.Shutdown(shutdownTimeout)
+	server := s.trackServer(s.newHTTPServer(addr))
+	return s.serveGracefully(s.serve(server.ListenAndServe), timeout...)
 }
 
 // RunTLSWithGracefulShutdown 启动HTTPS服务器并支持优雅关机
-// 使用TLS证书启动安全的HTTPS服务，同时支持优雅关机
+// 使用TLS证书启动安全的HTTPS服务，证书文件变化时自动重新加载，同时支持优雅关机
 // 参数 addr: 监听地址
 // 参数 certFile: TLS证书文件路径
 // 参数 keyFile: TLS私钥文件路径
 // 参数 timeout: 关闭超时时间，默认30秒
 // 返回值: error 启动或关闭过程中的错误信息
 func (s *Server) RunTLSWithGracefulShutdown(addr, certFile, keyFile string, timeout ...time.Duration) error {
+	tlsConfig, err := s.newFileTLSConfig(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	server := s.trackServer(s.newHTTPServer(addr))
+	server.TLSConfig = tlsConfig
+	return s.serveGracefully(s.serve(func() error {
+		return server.ListenAndServeTLS("", "")
+	}), timeout...)
+}
+
+// Start 根据配置启动服务器并支持优雅关机
+// 使用配置中的监听地址、TLS证书和关机超时时间；调用过Listen时启动所有注册的监听器
+// 返回值: error 启动或关闭过程中的错误信息
+func (s *Server) Start() error {
+	return s.Serve()
+}
+
+// serveGracefully 等待关闭信号，收到信号后优雅关闭
+// 参数 errCh: 服务错误通道
+// 参数 timeout: 关闭超时时间，默认30秒
+// 返回值: error 服务启动失败或关闭过程中的错误信息
+func (s *Server) serveGracefully(errCh <-chan error, timeout ...time.Duration) error {
 	// 设置默认超时时间
 	shutdownTimeout := 30 * time.Second
-	if len(timeout) > 0 {
+	if len(timeout) > 0 && timeout[0] > 0 {
 		shutdownTimeout = timeout[0]
 	}
-	
-	// 创建HTTP服务器实例
-	s.server = &http.Server{
-		Addr:    addr,
-		Handler: s.engine,
-	}
-	
-	// 在goroutine中启动HTTPS服务器
+
+	// 收到关闭信号或某个服务退出时，关闭所有服务
+	err := s.waitSignal(errCh)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return errors.Join(err, s.shutdown(ctx))
+}
+
+// serve 在goroutine中启动服务
+// 返回值: <-chan error 服务退出时接收其错误，优雅关闭导致的退出为nil
+func (s *Server) serve(serve func() error) <-chan error {
+	errCh := make(chan error, 1)
 	go func() {
-		if err := s.server.ListenAndServeTLS(certFile, keyFile); err != nil && err != http.ErrServerClosed {
-			// 服务器启动失败，发送信号通知主goroutine
-			s.quit <- syscall.SIGTERM
-		}
+		errCh <- serveError(serve())
 	}()
-	
-	// 优雅关机
-	return s.Shutdown(shutdownTimeout)
+	return errCh
+}
+
+// waitSignal 等待关闭信号或服务退出
+// 参数 errCh: 服务错误通道，可以为nil
+// 返回值: error 服务在收到信号前出错时的错误信息
+func (s *Server) waitSignal(errCh <-chan error) error {
+	signal.Notify(s.quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(s.quit)
+	restartCh, stopRestart := s.watchRestart()
+	defer stopRestart()
+
+	for {
+		select {
+		case <-s.quit:
+			return nil
+		case <-restartCh:
+			if s.restartOnSignal() {
+				return nil
+			}
+		case err := <-errCh:
+			if err != nil {
+				return fmt.Errorf("server error: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+// OnShutdown 注册优雅关闭时执行的函数
+// 函数在关闭监听器之前按注册顺序执行，此时仍在正常处理请求，
+// 可用于将就绪检查标记为失败并等待负载均衡器摘除流量；Stop不会执行这些函数
+// 参数 fn: 关闭函数，ctx为关闭超时上下文
+func (s *Server) OnShutdown(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, fn)
+}
+
+// shutdown 执行关闭函数后并发优雅关闭所有HTTP服务器
+func (s *Server) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	hooks := s.shutdownHooks
+	s.mu.Unlock()
+	for _, fn := range hooks {
+		fn(ctx)
+	}
+
+	servers := s.httpServers()
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = server.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// newHTTPServer 创建应用了超时、请求头限制和连接状态钩子的HTTP服务器实例
+func (s *Server) newHTTPServer(addr string) *http.Server {
+	cfg := s.cfg.Server
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.engine,
+		ReadTimeout:       cfg.Timeout.Read,
+		ReadHeaderTimeout: cfg.Timeout.ReadHeader,
+		WriteTimeout:      cfg.Timeout.Write,
+		IdleTimeout:       cfg.Timeout.Idle,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ConnState:         s.conns.onConnState,
+	}
 }
 
 // Stop 立即停止服务器
 // 强制关闭服务器，不等待正在处理的请求完成
 // 返回值: error 停止过程中的错误信息
 func (s *Server) Stop() error {
-	if s.server != nil {
-		return s.server.Close()
+	var errs []error
+	for _, server := range s.httpServers() {
+		errs = append(errs, server.Close())
 	}
-	return nil
+	return errors.Join(errs...)
 }
@@ -1,12 +1,41 @@
 package chi
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix 环境变量覆盖配置时使用的默认前缀
+// 例如 CHI_SERVER_ADDR 会覆盖 server.addr
+const DefaultEnvPrefix = "CHI"
+
+// 配置相关错误定义
+var (
+	ErrConfigFormat = errors.New("unsupported config file format")
+	ErrConfigMode   = errors.New("invalid server mode")
+)
+
+// Config 应用配置
+// 作为 NewWithConfig 的输入，支持从 YAML/JSON/TOML 文件和环境变量加载
 type Config struct {
+	// 服务器配置
+	Server ServerConfig `json:"server" yaml:"server"`
 }
 
 type ServerConfig struct {
-	// 服务器监听地址
+	// 服务器监听地址，为空时使用环境变量PORT，未设置PORT时为":8080"
 	Addr string `json:"addr" yaml:"addr"`
-	// 服务器模式
+	// 服务器模式："debug"、"release"、"test"，为空时保持当前的gin模式
 	Mode string `json:"mode" yaml:"mode"`
 	// 服务器名称
 	Name string `json:"name" yaml:"name"`
@@ -16,4 +45,290 @@ type ServerConfig struct {
 	Upload string `json:"upload" yaml:"upload"`
 	// 服务器版本
 	Version string `json:"version" yaml:"version"`
+	// 可信代理列表
+	TrustedProxies []string `json:"trusted_proxies" yaml:"trusted_proxies"`
+	// 获取客户端真实IP的请求头
+	RemoteIPHeaders []string `json:"remote_ip_headers" yaml:"remote_ip_headers"`
+	// 多部分表单最大内存（字节）
+	MaxMultipartMemory int64 `json:"max_multipart_memory" yaml:"max_multipart_memory"`
+	// 请求头最大字节数，为0时使用http.DefaultMaxHeaderBytes（1MB）
+	MaxHeaderBytes int `json:"max_header_bytes" yaml:"max_header_bytes"`
+	// 是否启用HTTP/2明文（h2c），仅用于未启用TLS的监听地址
+	H2C bool `json:"h2c" yaml:"h2c"`
+	// 是否在监听端口的UDP上同时提供HTTP/3服务，需要启用TLS
+	HTTP3 bool `json:"http3" yaml:"http3"`
+	// 错误响应是否使用真实的HTTP状态码，默认始终返回200
+	HTTPStatus bool `json:"http_status" yaml:"http_status"`
+	// 超时配置
+	Timeout TimeoutConfig `json:"timeout" yaml:"timeout"`
+	// TLS配置
+	TLS TLSConfig `json:"tls" yaml:"tls"`
+}
+
+// TimeoutConfig 服务器超时配置
+type TimeoutConfig struct {
+	// 读取请求的超时时间
+	Read time.Duration `json:"read" yaml:"read"`
+	// 读取请求头的超时时间，防止慢速请求头攻击（slowloris），为0时使用Read
+	ReadHeader time.Duration `json:"read_header" yaml:"read_header"`
+	// 写入响应的超时时间
+	Write time.Duration `json:"write" yaml:"write"`
+	// 空闲连接的超时时间
+	Idle time.Duration `json:"idle" yaml:"idle"`
+	// 优雅关机的超时时间
+	Shutdown time.Duration `json:"shutdown" yaml:"shutdown"`
+}
+
+// TLSConfig TLS配置
+type TLSConfig struct {
+	// 是否启用HTTPS
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 证书文件路径
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	// 私钥文件路径
+	KeyFile string `json:"key_file" yaml:"key_file"`
+	// 额外的证书，按客户端请求的SNI选择，未匹配时使用CertFile
+	Certificates []CertificateConfig `json:"certificates" yaml:"certificates"`
+	// 最低TLS版本："1.0"、"1.1"、"1.2"、"1.3"，默认1.2
+	MinVersion string `json:"min_version" yaml:"min_version"`
+	// 允许的密码套件名称，如"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"，为空时使用Go默认值（对TLS 1.3无效）
+	CipherSuites []string `json:"cipher_suites" yaml:"cipher_suites"`
+	// 证书文件变化检查间隔，为0时默认10秒，小于0时不重新加载
+	ReloadInterval time.Duration `json:"reload_interval" yaml:"reload_interval"`
+	// 验证客户端证书的CA文件路径，设置后启用mTLS
+	ClientCAFile string `json:"client_ca_file" yaml:"client_ca_file"`
+	// 客户端证书验证方式："none"、"request"、"require"、"verify_if_given"、"require_and_verify"，
+	// 设置ClientCAFile时默认为"require_and_verify"
+	ClientAuth string `json:"client_auth" yaml:"client_auth"`
+	// ACME自动证书配置
+	ACME ACMEConfig `json:"acme" yaml:"acme"`
+}
+
+// CertificateConfig 证书和私钥文件
+type CertificateConfig struct {
+	// 证书文件路径
+	CertFile string `json:"cert_file" yaml:"cert_file"`
+	// 私钥文件路径
+	KeyFile string `json:"key_file" yaml:"key_file"`
+}
+
+// ACMEConfig ACME自动证书配置
+// 通过ACME协议（如Let's Encrypt）自动申请和续期证书，支持TLS-ALPN-01和HTTP-01验证
+type ACMEConfig struct {
+	// 是否启用ACME
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 允许申请证书的域名
+	Domains []string `json:"domains" yaml:"domains"`
+	// 账号联系邮箱
+	Email string `json:"email" yaml:"email"`
+	// 证书缓存目录，默认"./certs"
+	CacheDir string `json:"cache_dir" yaml:"cache_dir"`
+	// ACME目录地址，为空时使用Let's Encrypt生产环境，测试时可指向pebble等本地服务
+	DirectoryURL string `json:"directory_url" yaml:"directory_url"`
+	// 信任的ACME服务CA证书文件，用于使用自签名证书的本地ACME服务
+	CAFile string `json:"ca_file" yaml:"ca_file"`
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Name:               "chi",
+			Upload:             "./uploads",
+			MaxMultipartMemory: 32 << 20,
+			Timeout: TimeoutConfig{
+				ReadHeader: 10 * time.Second,
+				Idle:       120 * time.Second,
+				Shutdown:   30 * time.Second,
+			},
+		},
+	}
+}
+
+// Validate 验证配置并补全默认值
+func (c *Config) Validate() error {
+	switch c.Server.Mode {
+	case "", gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		return fmt.Errorf("%w: %s", ErrConfigMode, c.Server.Mode)
+	}
+
+	if c.Server.MaxMultipartMemory <= 0 {
+		c.Server.MaxMultipartMemory = 32 << 20
+	}
+	if c.Server.Timeout.Shutdown <= 0 {
+		c.Server.Timeout.Shutdown = 30 * time.Second
+	}
+
+	if c.Server.H2C && c.Server.TLS.Enabled {
+		return errors.New("h2c cannot be used when tls is enabled")
+	}
+	if c.Server.HTTP3 && !c.Server.TLS.Enabled {
+		return errors.New("http3 requires tls to be enabled")
+	}
+
+	if tls := &c.Server.TLS; tls.Enabled {
+		if tls.ACME.Enabled {
+			if len(tls.ACME.Domains) == 0 {
+				return errors.New("tls acme domains are required when acme is enabled")
+			}
+			if tls.ACME.CacheDir == "" {
+				tls.ACME.CacheDir = "./certs"
+			}
+		} else if tls.CertFile == "" || tls.KeyFile == "" {
+			return errors.New("tls cert_file and key_file are required when tls is enabled")
+		}
+		if (tls.CertFile == "") != (tls.KeyFile == "") {
+			return errors.New("tls cert_file and key_file must be set together")
+		}
+		for _, cert := range tls.Certificates {
+			if cert.CertFile == "" || cert.KeyFile == "" {
+				return errors.New("tls certificates require cert_file and key_file")
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadConfig 从配置文件加载配置
+// 根据文件扩展名识别 YAML(.yaml/.yml)、JSON(.json)、TOML(.toml) 格式，
+// 在默认配置的基础上合并文件内容，再使用 DefaultEnvPrefix 前缀的环境变量覆盖
+// 参数 path: 配置文件路径，为空时仅使用默认配置和环境变量
+// 返回值: *Config 加载后的配置
+// 返回值: error 读取、解析或验证过程中的错误信息
+func LoadConfig(path string) (*Config, error) {
+	config := DefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := decodeConfig(filepath.Ext(path), data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	if err := config.LoadEnv(DefaultEnvPrefix); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return config, nil
+}
+
+// decodeConfig 按格式解析配置内容
+// JSON 是 YAML 的子集，TOML 先解析为通用结构后转换为 YAML，
+// 从而统一使用 yaml 标签解码，并支持 "30s" 形式的时间配置
+func decodeConfig(ext string, data []byte, config *Config) error {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml", ".json":
+	case ".toml":
+		var raw map[string]interface{}
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		converted, err := yaml.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		data = converted
+	default:
+		return fmt.Errorf("%w: %s", ErrConfigFormat, ext)
+	}
+	return yaml.Unmarshal(data, config)
+}
+
+// LoadEnv 使用环境变量覆盖配置
+// 环境变量名由前缀和 yaml 标签路径组成，如 CHI_SERVER_TIMEOUT_READ 对应 server.timeout.read
+// 切片类型使用逗号分隔，时间类型使用 time.ParseDuration 格式
+// 参数 prefix: 环境变量前缀
+// 返回值: error 环境变量值格式错误时返回
+func (c *Config) LoadEnv(prefix string) error {
+	return loadEnv(reflect.ValueOf(c).Elem(), strings.ToUpper(prefix))
+}
+
+// loadEnv 递归遍历结构体字段并应用环境变量
+func loadEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		key := prefix + "_" + strings.ToUpper(name)
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			if err := loadEnv(fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(fv, value); err != nil {
+			return fmt.Errorf("invalid environment variable %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setEnvValue 将环境变量字符串转换为字段对应的类型
+func setEnvValue(fv reflect.Value, value string) error {
+	if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", fv.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
 }
@@ -0,0 +1,141 @@
+package chi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		path := writeConfigFile(t, "config.yaml", `
+server:
+  addr: ":9000"
+  mode: release
+  trusted_proxies: ["10.0.0.1"]
+  timeout:
+    read: 5s
+`)
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, ":9000", cfg.Server.Addr)
+		assert.Equal(t, gin.ReleaseMode, cfg.Server.Mode)
+		assert.Equal(t, []string{"10.0.0.1"}, cfg.Server.TrustedProxies)
+		assert.Equal(t, 5*time.Second, cfg.Server.Timeout.Read)
+		// 未配置的项保留默认值
+		assert.Equal(t, 30*time.Second, cfg.Server.Timeout.Shutdown)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		path := writeConfigFile(t, "config.json", `{"server": {"addr": ":9001", "timeout": {"write": "1m"}}}`)
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, ":9001", cfg.Server.Addr)
+		assert.Equal(t, time.Minute, cfg.Server.Timeout.Write)
+	})
+
+	t.Run("TOML", func(t *testing.T) {
+		path := writeConfigFile(t, "config.toml", `
+[server]
+addr = ":9002"
+max_multipart_memory = 1024
+
+[server.timeout]
+idle = "90s"
+`)
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, ":9002", cfg.Server.Addr)
+		assert.Equal(t, int64(1024), cfg.Server.MaxMultipartMemory)
+		assert.Equal(t, 90*time.Second, cfg.Server.Timeout.Idle)
+	})
+
+	t.Run("EnvOverride", func(t *testing.T) {
+		path := writeConfigFile(t, "config.yaml", "server:\n  addr: \":9000\"\n")
+		t.Setenv("CHI_SERVER_ADDR", ":9100")
+		t.Setenv("CHI_SERVER_TIMEOUT_SHUTDOWN", "10s")
+		t.Setenv("CHI_SERVER_REMOTE_IP_HEADERS", "X-Real-IP, X-Forwarded-For")
+		t.Setenv("CHI_SERVER_TLS_ENABLED", "false")
+
+		cfg, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, ":9100", cfg.Server.Addr)
+		assert.Equal(t, 10*time.Second, cfg.Server.Timeout.Shutdown)
+		assert.Equal(t, []string{"X-Real-IP", "X-Forwarded-For"}, cfg.Server.RemoteIPHeaders)
+	})
+
+	t.Run("InvalidEnv", func(t *testing.T) {
+		t.Setenv("CHI_SERVER_TIMEOUT_READ", "soon")
+		_, err := LoadConfig("")
+		assert.Error(t, err)
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		path := writeConfigFile(t, "config.ini", "addr=:9000")
+		_, err := LoadConfig(path)
+		assert.ErrorIs(t, err, ErrConfigFormat)
+	})
+
+	t.Run("InvalidMode", func(t *testing.T) {
+		path := writeConfigFile(t, "config.yaml", "server:\n  mode: production\n")
+		_, err := LoadConfig(path)
+		assert.ErrorIs(t, err, ErrConfigMode)
+	})
+}
+
+func TestNewWithConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Mode = gin.TestMode
+	cfg.Server.Upload = filepath.Join(t.TempDir(), "uploads")
+	cfg.Server.TrustedProxies = []string{"127.0.0.1"}
+	cfg.Server.MaxMultipartMemory = 1 << 20
+
+	s, err := NewWithConfig(cfg)
+	require.NoError(t, err)
+	assert.Same(t, cfg, s.Config())
+	assert.Equal(t, int64(1<<20), s.Engine().MaxMultipartMemory)
+	assert.DirExists(t, s.UploadDir())
+
+	cfg = DefaultConfig()
+	cfg.Server.Mode = gin.TestMode
+	cfg.Server.Upload = ""
+	cfg.Server.TrustedProxies = []string{"not-an-ip"}
+	_, err = NewWithConfig(cfg)
+	assert.Error(t, err)
+
+	// 未设置Mode时保持调用方已设置的模式
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.TestMode)
+	cfg = DefaultConfig()
+	cfg.Server.Upload = ""
+	_, err = NewWithConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, gin.ReleaseMode, gin.Mode())
+}
+
+func TestDefaultAddr(t *testing.T) {
+	t.Setenv("PORT", "")
+	assert.Equal(t, ":8080", New().defaultAddr())
+
+	t.Setenv("PORT", "9000")
+	assert.Equal(t, ":9000", New().defaultAddr())
+
+	cfg := DefaultConfig()
+	cfg.Server.Addr = "127.0.0.1:7000"
+	cfg.Server.Upload = ""
+	s, err := NewWithConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:7000", s.defaultAddr())
+}
@@ -0,0 +1,86 @@
+package chi
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnStats 连接统计
+type ConnStats struct {
+	// Active 正在处理请求的连接数
+	Active int64 `json:"active"`
+	// Idle 空闲（keep-alive）的连接数
+	Idle int64 `json:"idle"`
+	// New 已建立但尚未发送请求的连接数
+	New int64 `json:"new"`
+	// Total 累计接受的连接数
+	Total uint64 `json:"total"`
+}
+
+// ConnStateHook 连接状态变化钩子
+type ConnStateHook func(conn net.Conn, state http.ConnState)
+
+// connTracker 跟踪所有监听器的连接状态
+type connTracker struct {
+	mu    sync.Mutex
+	conns map[net.Conn]http.ConnState
+	stats ConnStats
+	hooks []ConnStateHook
+}
+
+// OnConnState 注册连接状态变化钩子
+// 钩子在连接状态变化时同步调用，应尽快返回
+// 参数 hook: 连接状态变化钩子
+func (s *Server) OnConnState(hook ConnStateHook) {
+	s.conns.mu.Lock()
+	defer s.conns.mu.Unlock()
+	s.conns.hooks = append(s.conns.hooks, hook)
+}
+
+// ConnStats 获取当前的连接统计
+// 返回值: ConnStats 所有监听器的连接统计
+func (s *Server) ConnStats() ConnStats {
+	s.conns.mu.Lock()
+	defer s.conns.mu.Unlock()
+	return s.conns.stats
+}
+
+// onConnState 作为http.Server.ConnState更新连接统计并调用钩子
+func (t *connTracker) onConnState(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	if t.conns == nil {
+		t.conns = make(map[net.Conn]http.ConnState)
+	}
+	if prev, ok := t.conns[conn]; ok {
+		t.count(prev, -1)
+	}
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(t.conns, conn)
+	default:
+		if state == http.StateNew {
+			t.stats.Total++
+		}
+		t.conns[conn] = state
+		t.count(state, 1)
+	}
+	hooks := t.hooks
+	t.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(conn, state)
+	}
+}
+
+// count 调整指定状态的连接数
+func (t *connTracker) count(state http.ConnState, delta int64) {
+	switch state {
+	case http.StateNew:
+		t.stats.New += delta
+	case http.StateActive:
+		t.stats.Active += delta
+	case http.StateIdle:
+		t.stats.Idle += delta
+	}
+}
@@ -0,0 +1,79 @@
+package chi
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServerSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Mode = "test"
+	cfg.Server.Upload = t.TempDir()
+	cfg.Server.MaxHeaderBytes = 4096
+	cfg.Server.Timeout.Read = 5 * time.Second
+	cfg.Server.Timeout.Write = 6 * time.Second
+	s, err := NewWithConfig(cfg)
+	require.NoError(t, err)
+
+	server := s.newHTTPServer(":0")
+	assert.Equal(t, 10*time.Second, server.ReadHeaderTimeout)
+	assert.Equal(t, 5*time.Second, server.ReadTimeout)
+	assert.Equal(t, 6*time.Second, server.WriteTimeout)
+	assert.Equal(t, 120*time.Second, server.IdleTimeout)
+	assert.Equal(t, 4096, server.MaxHeaderBytes)
+	assert.NotNil(t, server.ConnState)
+}
+
+func TestConnStateAndWriteTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Mode = "test"
+	cfg.Server.Upload = t.TempDir()
+	cfg.Server.Timeout.Write = 50 * time.Millisecond
+	s, err := NewWithConfig(cfg)
+	require.NoError(t, err)
+
+	slow := func(c *Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	}
+	s.GET("/slow", slow)
+	s.GET("/stream", slow, WriteTimeout(0))
+
+	states := make(chan http.ConnState, 16)
+	s.OnConnState(func(_ net.Conn, state http.ConnState) { states <- state })
+
+	require.NoError(t, s.Listen("127.0.0.1:0"))
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+	base := "http://" + s.Addrs()[0].String()
+
+	resp, err := http.Get(base + "/stream")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "done", string(body))
+	assert.Equal(t, http.StateNew, <-states)
+	assert.Equal(t, http.StateActive, <-states)
+	assert.Equal(t, http.StateIdle, <-states)
+
+	stats := s.ConnStats()
+	assert.Equal(t, uint64(1), stats.Total)
+	assert.Equal(t, int64(1), stats.Idle)
+	assert.Equal(t, int64(0), stats.Active)
+
+	// 未覆盖写入超时的路由在服务器WriteTimeout后无法写出响应
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	_, err = client.Get(base + "/slow")
+	assert.Error(t, err)
+
+	s.quit <- syscall.SIGTERM
+	assert.NoError(t, <-errCh)
+}
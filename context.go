@@ -946,6 +946,15 @@ func (c *Context) Value(key interface{}) interface{} {
 	return c.Context.Value(key)
 }
 
+// server 获取处理当前请求的Server实例
+func (c *Context) server() *Server {
+	if v, ok := c.Context.Get(serverContextKey); ok {
+		s, _ := v.(*Server)
+		return s
+	}
+	return nil
+}
+
 // =============================================================================
 // 上下文复制方法
 // =============================================================================
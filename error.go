@@ -1,24 +1,183 @@
 package chi
 
-import "net/http"
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
 
+// Error 业务错误
+// Code为业务错误码，Status为对应的HTTP状态码，二者相互独立
 type Error struct {
-	Code    int
+	// Code 业务错误码
+	Code int
+	// Status HTTP状态码，在Server启用UseHTTPStatus时作为响应状态码
+	Status int
+	// Message 错误信息
 	Message string
+	// Details 错误详情，作为响应的data返回给客户端
+	Details map[string]any
+	// Meta 错误元数据，仅用于日志和排查，不返回给客户端
+	Meta map[string]any
+	// cause 原始错误
+	cause error
 }
 
+// errorRegistry 错误码注册表
+var errorRegistry = struct {
+	sync.RWMutex
+	errors map[int]*Error
+}{errors: make(map[int]*Error)}
+
+// NewError 创建业务错误
+// HTTP状态码由业务错误码推断：错误码本身是合法的HTTP状态码时直接使用，否则为500
+// 参数 code: 业务错误码
+// 参数 message: 错误信息，为空时使用状态码对应的默认描述
+// 返回值: *Error 新创建的错误
 func NewError(code int, message string) *Error {
+	status := http.StatusInternalServerError
+	if code >= 100 && code <= 599 {
+		status = code
+	}
+	return NewErrorWithStatus(status, code, message)
+}
+
+// NewErrorWithStatus 创建指定HTTP状态码的业务错误
+// 参数 status: HTTP状态码
+// 参数 code: 业务错误码
+// 参数 message: 错误信息，为空时使用状态码对应的默认描述
+// 返回值: *Error 新创建的错误
+func NewErrorWithStatus(status, code int, message string) *Error {
 	if message == "" {
-		panic("message cannot be nil")
+		message = http.StatusText(status)
+	}
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// RegisterError 创建并注册业务错误
+// 用于在包初始化时定义错误码，错误码重复时panic
+// 参数 status: HTTP状态码
+// 参数 code: 业务错误码
+// 参数 message: 错误信息
+// 返回值: *Error 注册的错误
+func RegisterError(status, code int, message string) *Error {
+	e := NewErrorWithStatus(status, code, message)
+
+	errorRegistry.Lock()
+	defer errorRegistry.Unlock()
+	if existing, ok := errorRegistry.errors[code]; ok {
+		panic(fmt.Sprintf("chi: error code %d already registered as %q", code, existing.Message))
 	}
-	return &Error{code, message}
+	errorRegistry.errors[code] = e
+	return e
+}
+
+// LookupError 根据业务错误码查找已注册的错误
+// 参数 code: 业务错误码
+// 返回值: *Error 注册的错误
+// 返回值: bool 是否存在
+func LookupError(code int) (*Error, bool) {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	e, ok := errorRegistry.errors[code]
+	return e, ok
 }
 
+// 预定义错误
 var (
-	ErrServer  = NewError(http.StatusInternalServerError, "服务异常")
-	ErrBinding = NewError(http.StatusBadRequest, "参数错误")
+	ErrServer           = RegisterError(http.StatusInternalServerError, http.StatusInternalServerError, "服务异常")
+	ErrBinding          = RegisterError(http.StatusBadRequest, http.StatusBadRequest, "参数错误")
+	ErrUnauthorized     = RegisterError(http.StatusUnauthorized, http.StatusUnauthorized, "未登录或登录已过期")
+	ErrForbidden        = RegisterError(http.StatusForbidden, http.StatusForbidden, "没有访问权限")
+	ErrNotFound         = RegisterError(http.StatusNotFound, http.StatusNotFound, "资源不存在")
+	ErrMethodNotAllowed = RegisterError(http.StatusMethodNotAllowed, http.StatusMethodNotAllowed, "请求方法不允许")
+	ErrConflict         = RegisterError(http.StatusConflict, http.StatusConflict, "资源冲突")
+	ErrTooManyRequests  = RegisterError(http.StatusTooManyRequests, http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
+	ErrUnavailable      = RegisterError(http.StatusServiceUnavailable, http.StatusServiceUnavailable, "服务暂不可用")
+	ErrTimeout          = RegisterError(http.StatusGatewayTimeout, http.StatusGatewayTimeout, "请求超时")
 )
 
+// Error 实现error接口
+// 存在原始错误时附带原始错误信息
 func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
 	return e.Message
 }
+
+// Unwrap 获取原始错误，支持errors.Is和errors.As
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 判断是否为同一业务错误
+// 业务错误码相同即视为相同，因此WithDetails等方法派生的错误仍与原错误匹配
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap 附加原始错误
+// 返回新的错误副本，不修改预定义错误
+// 参数 cause: 原始错误
+func (e *Error) Wrap(cause error) *Error {
+	c := e.clone()
+	c.cause = cause
+	return c
+}
+
+// WithMessage 替换错误信息
+// 返回新的错误副本，不修改预定义错误
+// 参数 message: 错误信息
+func (e *Error) WithMessage(message string) *Error {
+	c := e.clone()
+	c.Message = message
+	return c
+}
+
+// WithStatus 替换HTTP状态码
+// 返回新的错误副本，不修改预定义错误
+// 参数 status: HTTP状态码
+func (e *Error) WithStatus(status int) *Error {
+	c := e.clone()
+	c.Status = status
+	return c
+}
+
+// WithDetails 合并错误详情
+// 返回新的错误副本，不修改预定义错误
+// 参数 details: 错误详情，作为响应的data返回
+func (e *Error) WithDetails(details map[string]any) *Error {
+	c := e.clone()
+	c.Details = mergeMap(e.Details, details)
+	return c
+}
+
+// WithMeta 添加错误元数据
+// 返回新的错误副本，不修改预定义错误
+// 参数 key: 元数据键
+// 参数 value: 元数据值
+func (e *Error) WithMeta(key string, value any) *Error {
+	c := e.clone()
+	c.Meta = mergeMap(e.Meta, map[string]any{key: value})
+	return c
+}
+
+// clone 浅拷贝错误
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
+// mergeMap 合并两个map，返回新的map
+func mergeMap(base, extra map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,78 @@
+package chi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	t.Run("DefaultStatus", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, NewError(404, "").Status)
+		assert.Equal(t, "Not Found", NewError(404, "").Message)
+		assert.Equal(t, http.StatusInternalServerError, NewError(10001, "业务异常").Status)
+	})
+
+	t.Run("WrapAndIs", func(t *testing.T) {
+		err := ErrNotFound.Wrap(io.EOF).WithDetails(map[string]any{"id": 1})
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, "资源不存在: EOF", err.Error())
+		assert.Nil(t, ErrNotFound.Details, "predefined errors must not be modified")
+
+		var e *Error
+		require.True(t, errors.As(error(err), &e))
+		assert.Equal(t, map[string]any{"id": 1}, e.Details)
+	})
+
+	t.Run("WithMeta", func(t *testing.T) {
+		err := ErrForbidden.WithMeta("user", 7).WithMeta("tenant", "a")
+		assert.Equal(t, map[string]any{"user": 7, "tenant": "a"}, err.Meta)
+		assert.Nil(t, ErrForbidden.Meta)
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		e, ok := LookupError(http.StatusTooManyRequests)
+		require.True(t, ok)
+		assert.Same(t, ErrTooManyRequests, e)
+
+		custom := RegisterError(http.StatusConflict, 20001, "用户名已存在")
+		assert.Equal(t, http.StatusConflict, custom.Status)
+		assert.Panics(t, func() { RegisterError(http.StatusConflict, 20001, "重复") })
+	})
+}
+
+func TestResStatusMode(t *testing.T) {
+	register := func(s *Server) {
+		s.GET("/missing", func(c *Context) {
+			Res(c, ErrNotFound.WithDetails(map[string]any{"id": "42"}))
+		})
+		s.GET("/unknown", func(c *Context) {
+			Res(c, errors.New("boom"))
+		})
+	}
+
+	t.Run("AlwaysOK", func(t *testing.T) {
+		s := newTestServer()
+		register(s)
+		w := doRequest(s, http.MethodGet, "/missing", "", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(404), body["code"])
+		assert.Equal(t, map[string]interface{}{"id": "42"}, body["data"])
+	})
+
+	t.Run("HTTPStatus", func(t *testing.T) {
+		s := newTestServer()
+		s.UseHTTPStatus(true)
+		register(s)
+		assert.Equal(t, http.StatusNotFound, doRequest(s, http.MethodGet, "/missing", "", nil).Code)
+		w := doRequest(s, http.MethodGet, "/unknown", "", nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, float64(500), decodeResponse(t, w)["code"])
+	})
+}
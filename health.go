@@ -0,0 +1,357 @@
+package chi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// 健康状态
+const (
+	// HealthUp 所有检查通过
+	HealthUp = "up"
+	// HealthDegraded 非关键检查失败，服务仍可用
+	HealthDegraded = "degraded"
+	// HealthDown 关键检查失败或服务正在关闭
+	HealthDown = "down"
+)
+
+// errDraining 服务正在关闭时就绪检查返回的错误
+var errDraining = errors.New("server is shutting down")
+
+// Check 健康检查项
+type Check struct {
+	// Name 检查项名称，如"mysql"、"redis"
+	Name string
+	// Check 检查函数，返回nil表示健康
+	Check func(ctx context.Context) error
+	// Timeout 单次检查的超时时间，为0时使用HealthConfig.Timeout
+	Timeout time.Duration
+	// Optional 是否为非关键检查，非关键检查失败时整体状态为degraded并返回200，
+	// 默认所有检查项都是关键检查，失败时整体状态为down并返回503，负载均衡器会摘除流量
+	Optional bool
+	// CacheTTL 检查结果的缓存时间，为0时使用HealthConfig.CacheTTL，小于0时不缓存
+	CacheTTL time.Duration
+	// Liveness 是否参与存活检查（/livez），通常只有进程自身的检查需要参与，如死锁检测
+	Liveness bool
+}
+
+// CheckResult 单个检查项的结果
+type CheckResult struct {
+	// Status 检查状态：up或down
+	Status string `json:"status"`
+	// Critical 是否为关键检查
+	Critical bool `json:"critical"`
+	// Error 检查失败的错误信息
+	Error string `json:"error,omitempty"`
+	// Duration 检查耗时（毫秒）
+	Duration float64 `json:"duration_ms"`
+	// CheckedAt 检查时间，使用缓存时为缓存结果的检查时间
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport 健康检查报告
+type HealthReport struct {
+	// Status 整体状态：up、degraded或down
+	Status string `json:"status"`
+	// Checks 各检查项的结果
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	// HealthPath 完整健康检查路径，执行所有检查项
+	HealthPath string
+	// ReadyPath 就绪检查路径，执行所有检查项，服务关闭时返回失败
+	ReadyPath string
+	// LivePath 存活检查路径，只执行Liveness检查项
+	LivePath string
+	// Timeout 默认的单次检查超时时间
+	Timeout time.Duration
+	// CacheTTL 默认的检查结果缓存时间
+	CacheTTL time.Duration
+	// DrainDelay 服务关闭时，就绪检查变为失败后等待多久再关闭监听器，让负载均衡器先摘除流量
+	DrainDelay time.Duration
+	// ShowErrors 是否在HTTP端点的响应中返回检查失败的错误信息，
+	// 错误信息可能包含内部地址等敏感信息，默认只返回状态
+	ShowErrors bool
+}
+
+// DefaultHealthConfig 默认健康检查配置
+var DefaultHealthConfig = HealthConfig{
+	HealthPath: "/healthz",
+	ReadyPath:  "/readyz",
+	LivePath:   "/livez",
+	Timeout:    5 * time.Second,
+	CacheTTL:   time.Second,
+}
+
+// Health 健康检查
+// 汇总数据库、MongoDB、Redis、调度器等组件的健康状态，提供/healthz、/readyz、/livez端点
+type Health struct {
+	config HealthConfig
+
+	mu sync.RWMutex
+	// checks 已注册的检查项
+	checks []*healthCheck
+	// draining 服务是否正在关闭
+	draining atomic.Bool
+}
+
+// healthCheck 检查项及其缓存的结果
+type healthCheck struct {
+	Check
+	mu     sync.Mutex
+	result CheckResult
+	cached bool
+}
+
+// =============================================================================
+// 构造函数
+// =============================================================================
+
+// NewHealth 创建健康检查
+// 参数 config: 可选的健康检查配置，默认使用DefaultHealthConfig
+// 返回值: *Health 健康检查实例
+func NewHealth(config ...HealthConfig) *Health {
+	cfg := DefaultHealthConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.HealthPath == "" {
+		cfg.HealthPath = DefaultHealthConfig.HealthPath
+	}
+	if cfg.ReadyPath == "" {
+		cfg.ReadyPath = DefaultHealthConfig.ReadyPath
+	}
+	if cfg.LivePath == "" {
+		cfg.LivePath = DefaultHealthConfig.LivePath
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultHealthConfig.Timeout
+	}
+	return &Health{config: cfg}
+}
+
+// =============================================================================
+// 检查项
+// =============================================================================
+
+// Register 注册检查项，同名检查项会被替换
+// 参数 check: 检查项
+func (h *Health) Register(check Check) {
+	if check.Timeout <= 0 {
+		check.Timeout = h.config.Timeout
+	}
+	if check.CacheTTL == 0 {
+		check.CacheTTL = h.config.CacheTTL
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range h.checks {
+		if c.Name == check.Name {
+			h.checks[i] = &healthCheck{Check: check}
+			return
+		}
+	}
+	h.checks = append(h.checks, &healthCheck{Check: check})
+}
+
+// HealthCheckable 提供HealthCheck方法的客户端，如pkg/database.Client和pkg/mongo.Client
+type HealthCheckable interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Pinger 提供Ping方法的客户端，如pkg/cache.Client和pkg/database.Client
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// StatusReporter 提供IsHealthy方法的组件，如pkg/scheduler.DefaultMonitor
+type StatusReporter interface {
+	IsHealthy() bool
+}
+
+// HealthChecker 将提供HealthCheck方法的客户端转换为检查函数
+// 参数 client: 客户端
+// 返回值: func(ctx context.Context) error 检查函数
+func HealthChecker(client HealthCheckable) func(ctx context.Context) error {
+	return client.HealthCheck
+}
+
+// PingChecker 将提供Ping方法的客户端转换为检查函数
+// 参数 client: 客户端
+// 返回值: func(ctx context.Context) error 检查函数
+func PingChecker(client Pinger) func(ctx context.Context) error {
+	return client.Ping
+}
+
+// StatusChecker 将提供IsHealthy方法的组件转换为检查函数
+// 参数 component: 组件
+// 返回值: func(ctx context.Context) error 检查函数
+func StatusChecker(component StatusReporter) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if !component.IsHealthy() {
+			return errors.New("unhealthy")
+		}
+		return nil
+	}
+}
+
+// =============================================================================
+// 检查执行
+// =============================================================================
+
+// Check 执行所有检查项
+// 参数 ctx: 上下文
+// 返回值: HealthReport 健康检查报告
+func (h *Health) Check(ctx context.Context) HealthReport {
+	return h.run(ctx, false)
+}
+
+// Ready 执行就绪检查，服务正在关闭时整体状态为down
+// 参数 ctx: 上下文
+// 返回值: HealthReport 健康检查报告
+func (h *Health) Ready(ctx context.Context) HealthReport {
+	report := h.run(ctx, false)
+	if h.draining.Load() {
+		report.Status = HealthDown
+		report.Checks["shutdown"] = CheckResult{Status: HealthDown, Critical: true, Error: errDraining.Error(), CheckedAt: time.Now()}
+	}
+	return report
+}
+
+// Live 执行存活检查，只包含Liveness检查项
+// 参数 ctx: 上下文
+// 返回值: HealthReport 健康检查报告
+func (h *Health) Live(ctx context.Context) HealthReport {
+	return h.run(ctx, true)
+}
+
+// Drain 将就绪检查标记为失败，服务关闭时自动调用
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// run 并发执行检查项并汇总结果
+// 参数 liveness: 是否只执行Liveness检查项
+func (h *Health) run(ctx context.Context, liveness bool) HealthReport {
+	h.mu.RLock()
+	var checks []*healthCheck
+	for _, c := range h.checks {
+		if !liveness || c.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthUp, Checks: make(map[string]CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.Name] = results[i]
+		if results[i].Status == HealthUp {
+			continue
+		}
+		if !c.Optional {
+			report.Status = HealthDown
+		} else if report.Status == HealthUp {
+			report.Status = HealthDegraded
+		}
+	}
+	return report
+}
+
+// run 执行检查，缓存未过期时直接返回缓存的结果
+// 结果会被缓存时不使用请求的取消信号，避免客户端断开或请求超时导致在CacheTTL内一直返回down
+func (c *healthCheck) run(ctx context.Context) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.CacheTTL > 0 {
+		if c.cached && time.Since(c.result.CheckedAt) < c.CacheTTL {
+			return c.result
+		}
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := runHook(ctx, c.Check.Check)
+
+	result := CheckResult{
+		Status:    HealthUp,
+		Critical:  !c.Optional,
+		Duration:  float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = HealthDown
+		result.Error = err.Error()
+	}
+	c.result, c.cached = result, true
+	return result
+}
+
+// =============================================================================
+// HTTP端点
+// =============================================================================
+
+// Mount 在服务器上注册健康检查端点，并在服务关闭时将就绪检查标记为失败
+// 关闭时先等待DrainDelay，让负载均衡器通过/readyz发现并摘除流量，再关闭监听器
+// 参数 s: 服务器
+func (h *Health) Mount(s *Server) {
+	s.GET(h.config.HealthPath, h.handler(h.Check))
+	s.GET(h.config.ReadyPath, h.handler(h.Ready))
+	s.GET(h.config.LivePath, h.handler(h.Live))
+
+	s.OnShutdown(func(ctx context.Context) {
+		h.Drain()
+		if h.config.DrainDelay <= 0 {
+			return
+		}
+		timer := time.NewTimer(h.config.DrainDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// handler 创建输出健康检查报告的处理函数
+// 整体状态为down时返回503，否则返回200；未启用ShowErrors时不返回错误信息
+func (h *Health) handler(check func(ctx context.Context) HealthReport) HandlerFunc {
+	return func(c *Context) {
+		report := check(c.Context.Request.Context())
+		if !h.config.ShowErrors {
+			for name, result := range report.Checks {
+				result.Error = ""
+				report.Checks[name] = result
+			}
+		}
+		status := http.StatusOK
+		if report.Status == HealthDown {
+			status = http.StatusServiceUnavailable
+		}
+		c.Header("Cache-Control", "no-store")
+		c.JSON(status, report)
+	}
+}
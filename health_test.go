@@ -0,0 +1,167 @@
+package chi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"chi/pkg/cache"
+	"chi/pkg/database"
+	"chi/pkg/mongo"
+	"chi/pkg/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pkg客户端可以直接用于健康检查
+var (
+	_ HealthCheckable = (*database.Client)(nil)
+	_ HealthCheckable = (*mongo.Client)(nil)
+	_ Pinger          = (*cache.Client)(nil)
+	_ StatusReporter  = (*scheduler.DefaultMonitor)(nil)
+)
+
+// statusFunc 用于测试的StatusReporter
+type statusFunc func() bool
+
+func (f statusFunc) IsHealthy() bool { return f() }
+
+func TestHealthEndpoints(t *testing.T) {
+	s := newTestServer()
+	h := NewHealth(HealthConfig{ShowErrors: true})
+	h.Mount(s)
+
+	var redisDown atomic.Bool
+	h.Register(Check{Name: "mysql", Check: func(ctx context.Context) error { return nil }})
+	h.Register(Check{Name: "redis", Optional: true, CacheTTL: -1, Check: func(ctx context.Context) error {
+		if redisDown.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}})
+	h.Register(Check{Name: "scheduler", Liveness: true, Check: StatusChecker(statusFunc(func() bool { return true }))})
+
+	report := func(path string) (int, HealthReport) {
+		w := doRequest(s, http.MethodGet, path, "", nil)
+		var r HealthReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &r))
+		return w.Code, r
+	}
+
+	code, r := report("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthUp, r.Status)
+	assert.Len(t, r.Checks, 3)
+	assert.True(t, r.Checks["mysql"].Critical)
+	assert.False(t, r.Checks["redis"].Critical)
+
+	// 非关键检查失败时为degraded
+	redisDown.Store(true)
+	code, r = report("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthDegraded, r.Status)
+	assert.Equal(t, "connection refused", r.Checks["redis"].Error)
+
+	// 检查项默认为关键检查，失败时为down
+	h.Register(Check{Name: "mysql", Check: func(ctx context.Context) error { return errors.New("gone") }})
+	code, r = report("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthDown, r.Status)
+
+	// 存活检查只包含Liveness检查项
+	code, r = report("/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthUp, r.Status)
+	assert.Len(t, r.Checks, 1)
+	assert.Contains(t, r.Checks, "scheduler")
+}
+
+func TestHealthHidesErrors(t *testing.T) {
+	s := newTestServer()
+	h := NewHealth()
+	h.Mount(s)
+	h.Register(Check{Name: "mysql", Check: func(ctx context.Context) error { return errors.New("dial tcp 10.0.0.5:3306: refused") }})
+
+	w := doRequest(s, http.MethodGet, "/readyz", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+
+	// 直接调用时仍然返回错误信息
+	assert.NotEmpty(t, h.Ready(context.Background()).Checks["mysql"].Error)
+}
+
+func TestHealthTimeoutAndCache(t *testing.T) {
+	h := NewHealth(HealthConfig{Timeout: 20 * time.Millisecond, CacheTTL: time.Minute})
+
+	var calls atomic.Int32
+	h.Register(Check{Name: "slow", Check: func(ctx context.Context) error {
+		calls.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	r := h.Check(context.Background())
+	assert.Equal(t, HealthDown, r.Status)
+	assert.Contains(t, r.Checks["slow"].Error, "deadline exceeded")
+
+	// 缓存时间内不重复执行
+	h.Check(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHealthCacheIgnoresRequestCancel(t *testing.T) {
+	h := NewHealth(HealthConfig{Timeout: time.Second, CacheTTL: time.Minute})
+	h.Register(Check{Name: "mysql", Check: func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		}
+	}})
+
+	// 请求已取消时检查仍然完成，不缓存down
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, HealthUp, h.Check(ctx).Status)
+	assert.Equal(t, HealthUp, h.Check(context.Background()).Status)
+
+	// 不缓存的检查项仍然使用请求上下文
+	h.Register(Check{Name: "mysql", CacheTTL: -1, Check: func(ctx context.Context) error { return ctx.Err() }})
+	assert.Equal(t, HealthDown, h.Check(ctx).Status)
+}
+
+func TestHealthDrainOnShutdown(t *testing.T) {
+	s := newTestServer()
+	h := NewHealth(HealthConfig{DrainDelay: 300 * time.Millisecond})
+	h.Mount(s)
+	require.NoError(t, s.Listen("127.0.0.1:0"))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+	url := "http://" + s.Addrs()[0].String()
+
+	status := func(path string) int {
+		resp, err := http.Get(url + path)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, status("/readyz"))
+
+	// 关闭时先将就绪检查标记为失败，监听器在DrainDelay后才关闭
+	s.quit <- syscall.SIGTERM
+	require.Eventually(t, func() bool { return status("/readyz") == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusOK, status("/livez"))
+	assert.Equal(t, http.StatusOK, status("/healthz"))
+	require.NoError(t, <-errCh)
+}
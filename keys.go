@@ -0,0 +1,96 @@
+package chi
+
+import (
+	"context"
+
+	"chi/pkg/logger"
+)
+
+// =============================================================================
+// 类型化上下文键
+// =============================================================================
+
+// Key 类型化的上下文键
+// 值存储在请求的上下文数据中，通过SetValue和GetValue读写，避免类型断言
+type Key[T any] struct {
+	name string
+}
+
+// NewKey 创建类型化的上下文键
+// 参数 name: 键名，与Context.Set/Get使用同一命名空间，应保证唯一
+// 返回值: Key[T] 上下文键
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name 获取键名
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// 预定义的上下文键，Std会将其值写入context.Context
+var (
+	// RequestIDKey 请求ID
+	RequestIDKey = NewKey[string]("request_id")
+	// UserIDKey 当前用户ID
+	UserIDKey = NewKey[string]("user_id")
+	// LoggerKey 请求范围的日志记录器，由middlewares.AccessLog设置
+	LoggerKey = NewKey[*logger.Logger]("logger")
+)
+
+// SetValue 在上下文中设置类型化的值
+// 参数 c: 请求上下文
+// 参数 key: 上下文键
+// 参数 value: 要存储的值
+func SetValue[T any](c *Context, key Key[T], value T) {
+	c.Context.Set(key.name, value)
+}
+
+// GetValue 获取上下文中类型化的值
+// 参数 c: 请求上下文
+// 参数 key: 上下文键
+// 返回值: T 存储的值，不存在或类型不匹配时为零值
+// 返回值: bool 值是否存在且类型匹配
+func GetValue[T any](c *Context, key Key[T]) (T, bool) {
+	if v, ok := c.Context.Get(key.name); ok {
+		value, ok := v.(T)
+		return value, ok
+	}
+	var zero T
+	return zero, false
+}
+
+// =============================================================================
+// 标准上下文
+// =============================================================================
+
+// Std 获取携带请求信息的标准context.Context
+// 基于请求的context.Context，保留其截止时间和取消信号，并写入请求ID、用户ID和链路追踪信息，
+// 可直接传递给pkg/database、pkg/mongo、pkg/cache等包，pkg/logger.ContextFields可从中提取日志字段。
+// 返回的上下文不引用Context本身，可以在goroutine中使用，但会随请求结束而取消
+// 返回值: context.Context 标准上下文
+func (c *Context) Std() context.Context {
+	ctx := c.Context.Request.Context()
+
+	if id := requestID(c); id != "" {
+		ctx = logger.WithRequestID(ctx, id)
+	}
+	if userID, ok := GetValue(c, UserIDKey); ok && userID != "" {
+		ctx = logger.WithUserID(ctx, userID)
+	}
+	if traceID, spanID := traceParent(c); traceID != "" {
+		ctx = logger.WithTrace(ctx, traceID, spanID)
+	}
+	return ctx
+}
+
+// Logger 获取请求范围的日志记录器
+// 优先使用LoggerKey中的记录器（middlewares.AccessLog会设置），
+// 否则基于全局日志记录器创建，两者都附带请求ID、用户ID和链路追踪字段
+// 返回值: *logger.Logger 日志记录器
+func (c *Context) Logger() *logger.Logger {
+	if l, ok := GetValue(c, LoggerKey); ok && l != nil {
+		return l
+	}
+	return logger.GetGlobal().WithContext(c.Std())
+}
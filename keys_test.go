@@ -0,0 +1,66 @@
+package chi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"chi/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedKeys(t *testing.T) {
+	s := newTestServer()
+	countKey := NewKey[int]("count")
+
+	var std context.Context
+	s.Use(func(c *Context) {
+		SetValue(c, countKey, 3)
+		SetValue(c, UserIDKey, "u-1")
+		c.Next()
+	})
+	s.GET("/ping", func(c *Context) {
+		count, ok := GetValue(c, countKey)
+		assert.True(t, ok)
+		assert.Equal(t, 3, count)
+
+		_, ok = GetValue(c, NewKey[string]("count"))
+		assert.False(t, ok, "type mismatch")
+		_, ok = GetValue(c, NewKey[int]("missing"))
+		assert.False(t, ok)
+
+		std = c.Std()
+		c.Status(http.StatusNoContent)
+	})
+
+	doRequest(s, http.MethodGet, "/ping", "", map[string]string{
+		"X-Request-ID": "req-1",
+		"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+
+	assert.Equal(t, "req-1", logger.RequestIDFromContext(std))
+	assert.Equal(t, "u-1", logger.UserIDFromContext(std))
+	traceID, spanID := logger.TraceFromContext(std)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	assert.Equal(t, "00f067aa0ba902b7", spanID)
+	assert.Len(t, logger.ContextFields(std), 4)
+}
+
+func TestContextLogger(t *testing.T) {
+	s := newTestServer()
+	custom, err := logger.NewLogger(nil)
+	assert.NoError(t, err)
+
+	var fallback, scoped *logger.Logger
+	s.GET("/log", func(c *Context) {
+		fallback = c.Logger()
+		SetValue(c, LoggerKey, custom)
+		scoped = c.Logger()
+		c.Status(http.StatusNoContent)
+	})
+	doRequest(s, http.MethodGet, "/log", "", nil)
+
+	assert.NotNil(t, fallback)
+	assert.Same(t, custom, scoped)
+}
@@ -0,0 +1,393 @@
+package chi
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// ListenOption 监听器选项
+type ListenOption func(*listener)
+
+// listener 通过Listen注册的监听器
+type listener struct {
+	// name 监听器名称
+	name string
+	// addr 注册时的原始地址
+	addr string
+	// network 网络类型：tcp、unix、fd、systemd
+	network string
+	// address 去掉协议前缀后的地址
+	address string
+	// ln 预先打开的监听器
+	ln net.Listener
+	// certFile、keyFile TLS证书和私钥文件
+	certFile, keyFile string
+	// tlsConfig TLS配置
+	tlsConfig *tls.Config
+	// handler 处理请求的Handler，为nil时使用Server
+	handler http.Handler
+	// paths 只放行的路径前缀，为空时放行所有路径
+	paths []string
+	// h2c 是否启用HTTP/2明文
+	h2c bool
+	// http3 是否在同一端口的UDP上启用HTTP/3
+	http3 bool
+	// bound 绑定后的监听器
+	bound net.Listener
+	// packetConn 绑定后的HTTP/3 UDP连接
+	packetConn net.PacketConn
+}
+
+// systemd socket activation 约定的第一个文件描述符
+const listenFdsStart = 3
+
+// =============================================================================
+// 监听器选项
+// =============================================================================
+
+// WithName 设置监听器名称
+// 参数 name: 名称，如"public"、"admin"
+func WithName(name string) ListenOption {
+	return func(l *listener) {
+		l.name = name
+	}
+}
+
+// WithTLS 使用证书文件启用TLS，证书文件变化时自动重新加载
+// 最低版本、密码套件和客户端证书验证使用服务器配置中的TLS参数
+// 参数 certFile: TLS证书文件路径
+// 参数 keyFile: TLS私钥文件路径
+func WithTLS(certFile, keyFile string) ListenOption {
+	return func(l *listener) {
+		l.certFile, l.keyFile = certFile, keyFile
+	}
+}
+
+// WithTLSConfig 使用自定义TLS配置启用TLS
+// 参数 config: TLS配置，需包含证书或GetCertificate，可使用TLSManager.TLSConfig创建
+func WithTLSConfig(config *tls.Config) ListenOption {
+	return func(l *listener) {
+		l.tlsConfig = config
+	}
+}
+
+// WithH2C 在该监听器上启用HTTP/2明文（h2c），同时支持prior knowledge和HTTP/1.1 Upgrade两种方式
+// 不能与TLS同时使用，TLS监听器会自动协商HTTP/2
+func WithH2C() ListenOption {
+	return func(l *listener) {
+		l.h2c = true
+	}
+}
+
+// WithHTTP3 在该监听器端口的UDP上同时提供HTTP/3服务，并通过Alt-Svc响应头通告
+// 需要同时启用TLS，只支持TCP地址
+func WithHTTP3() ListenOption {
+	return func(l *listener) {
+		l.http3 = true
+	}
+}
+
+// WithHandler 使用独立的Handler处理该监听器的请求
+// 参数 handler: HTTP处理器，可以是另一个Server
+func WithHandler(handler http.Handler) ListenOption {
+	return func(l *listener) {
+		l.handler = handler
+	}
+}
+
+// WithPaths 只在该监听器上暴露指定路径前缀下的路由，其他路径返回404
+// 按路径段匹配，"/admin"匹配"/admin"和"/admin/users"，不匹配"/administrator"；
+// 与WithHandler同时使用时对其Handler生效，与选项顺序无关
+// 参数 prefixes: 路径前缀，如"/healthz"、"/admin"
+func WithPaths(prefixes ...string) ListenOption {
+	return func(l *listener) {
+		l.paths = append(l.paths, prefixes...)
+	}
+}
+
+// WithListener 使用预先打开的net.Listener
+// 参数 ln: 监听器，Server关闭时会将其关闭
+func WithListener(ln net.Listener) ListenOption {
+	return func(l *listener) {
+		l.ln = ln
+	}
+}
+
+// =============================================================================
+// 监听器注册
+// =============================================================================
+
+// Listen 注册监听器，可多次调用
+// 所有监听器在Serve、Start或App.Run时一起启动，收到关闭信号时一起优雅关闭。
+// 地址格式：
+//   - ":8080"、"127.0.0.1:9090"、"tcp://:8080"：TCP
+//   - "unix:/run/app.sock"：Unix socket，启动前会删除已存在的socket文件
+//   - "fd:3"：继承的文件描述符
+//   - "systemd:0"、"systemd:http"：systemd socket activation，按序号或FileDescriptorName选择
+//
+// 参数 addr: 监听地址
+// 参数 opts: 监听器选项
+// 返回值: error 地址格式错误
+func (s *Server) Listen(addr string, opts ...ListenOption) error {
+	l := &listener{addr: addr, network: "tcp", address: addr}
+	if network, address, ok := strings.Cut(addr, ":"); ok {
+		switch network {
+		case "tcp", "unix", "fd", "systemd":
+			l.network = network
+			l.address = strings.TrimPrefix(address, "//")
+		}
+	}
+	if l.network == "fd" {
+		if _, err := strconv.Atoi(l.address); err != nil {
+			return fmt.Errorf("invalid listen address %s: %w", addr, err)
+		}
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.name == "" {
+		l.name = addr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+	return nil
+}
+
+// Serve 启动所有监听器并支持优雅关机
+// 未调用Listen时根据配置的监听地址和TLS设置启动
+// 返回值: error 绑定、服务或关闭过程中的错误信息
+func (s *Server) Serve() error {
+	errCh, err := s.startListeners()
+	if err != nil {
+		return err
+	}
+	return s.serveGracefully(errCh, s.cfg.Server.Timeout.Shutdown)
+}
+
+// Addrs 获取已绑定监听器的实际地址
+// 返回值: []net.Addr 地址列表，监听器启动前为空
+func (s *Server) Addrs() []net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var addrs []net.Addr
+	for _, l := range s.listeners {
+		if l.bound != nil {
+			addrs = append(addrs, l.bound.Addr())
+		}
+	}
+	return addrs
+}
+
+// =============================================================================
+// 监听器启动
+// =============================================================================
+
+// startListeners 绑定所有监听器并在后台启动服务
+// 任一监听器绑定失败时关闭已绑定的监听器并返回错误
+// 返回值: <-chan error 任一服务退出时接收其错误
+// 返回值: error 绑定失败的错误信息
+func (s *Server) startListeners() (<-chan error, error) {
+	s.mu.Lock()
+	if len(s.listeners) == 0 {
+		cfg := s.cfg.Server
+		addr := s.defaultAddr()
+		l := &listener{name: addr, addr: addr, network: "tcp", address: addr}
+		if s.tls != nil {
+			l.tlsConfig = s.tls.TLSConfig()
+		}
+		l.h2c, l.http3 = cfg.H2C, cfg.HTTP3
+		s.listeners = append(s.listeners, l)
+	}
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, l := range listeners {
+		if l.certFile != "" && l.tlsConfig == nil {
+			tlsConfig, err := s.newFileTLSConfig(l.certFile, l.keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure tls for %s: %w", l.name, err)
+			}
+			l.tlsConfig = tlsConfig
+		}
+		if l.h2c && l.tlsConfig != nil {
+			return nil, fmt.Errorf("h2c cannot be used with tls on %s", l.name)
+		}
+		if l.http3 && (l.tlsConfig == nil || l.network != "tcp") {
+			return nil, fmt.Errorf("http3 requires tls on a tcp address on %s", l.name)
+		}
+	}
+	for i, l := range listeners {
+		if err := s.bind(l); err != nil {
+			for _, bound := range listeners[:i] {
+				bound.close()
+			}
+			return nil, fmt.Errorf("failed to listen on %s: %w", l.name, err)
+		}
+	}
+
+	errCh := make(chan error, 2*len(listeners))
+	for _, l := range listeners {
+		server := s.trackServer(s.newHTTPServer(l.address))
+		if l.handler != nil {
+			server.Handler = l.handler
+		}
+		if len(l.paths) > 0 {
+			server.Handler = pathFilter{prefixes: l.paths, next: server.Handler}
+		}
+		if l.packetConn != nil {
+			server.Handler = s.serveHTTP3(l, server.Handler, errCh)
+		}
+		if l.h2c {
+			enableH2C(server)
+		}
+		go func() {
+			var err error
+			if l.tlsConfig != nil {
+				server.TLSConfig = l.tlsConfig
+				err = server.ServeTLS(l.bound, "", "")
+			} else {
+				err = server.Serve(l.bound)
+			}
+			if l.network == "unix" && !s.isHandedOff() {
+				os.Remove(l.address)
+			}
+			if err = serveError(err); err != nil {
+				err = fmt.Errorf("%s: %w", l.name, err)
+			}
+			errCh <- err
+		}()
+	}
+	closeInherited()
+	notifyReady()
+	return errCh, nil
+}
+
+// bind 绑定监听器，启用HTTP/3时同时绑定相同地址的UDP端口
+// 平滑重启后的新进程优先使用从父进程继承的监听器
+func (s *Server) bind(l *listener) error {
+	ln, err := inheritListener(l.addr)
+	if err == nil && ln == nil {
+		ln, err = l.listen()
+	}
+	if err != nil {
+		return err
+	}
+	var pc net.PacketConn
+	if l.http3 {
+		pc, err = inheritPacketConn(l.addr)
+		if err == nil && pc == nil {
+			pc, err = net.ListenPacket("udp", ln.Addr().String())
+		}
+		if err != nil {
+			ln.Close()
+			return err
+		}
+	}
+	s.mu.Lock()
+	l.bound, l.packetConn = ln, pc
+	s.mu.Unlock()
+	return nil
+}
+
+// close 关闭已绑定的监听器
+func (l *listener) close() {
+	if l.bound != nil {
+		l.bound.Close()
+	}
+	if l.packetConn != nil {
+		l.packetConn.Close()
+	}
+}
+
+// listen 绑定监听器
+func (l *listener) listen() (net.Listener, error) {
+	if l.ln != nil {
+		return l.ln, nil
+	}
+	switch l.network {
+	case "unix":
+		if err := os.Remove(l.address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return net.Listen("unix", l.address)
+	case "fd":
+		fd, _ := strconv.Atoi(l.address)
+		return fileListener(fd)
+	case "systemd":
+		fd, err := systemdFd(l.address)
+		if err != nil {
+			return nil, err
+		}
+		return fileListener(fd)
+	default:
+		return net.Listen("tcp", l.address)
+	}
+}
+
+// fileListener 从文件描述符创建监听器
+func fileListener(fd int) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd@%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// systemdFd 根据序号或名称查找systemd传递的文件描述符
+// 参数 name: 序号（从0开始）或FileDescriptorName
+func systemdFd(name string) (int, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return 0, errors.New("no sockets passed by systemd")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return 0, errors.New("no sockets passed by systemd")
+	}
+
+	index, err := strconv.Atoi(name)
+	if err != nil {
+		index = -1
+		for i, n := range strings.Split(os.Getenv("LISTEN_FDNAMES"), ":") {
+			if n == name {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("systemd socket %s not found", name)
+	}
+	return listenFdsStart + index, nil
+}
+
+// pathFilter 只放行指定路径前缀的请求
+type pathFilter struct {
+	prefixes []string
+	next     http.Handler
+}
+
+// ServeHTTP 实现http.Handler接口
+func (f pathFilter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.Path
+	for _, prefix := range f.prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			f.next.ServeHTTP(w, req)
+			return
+		}
+	}
+	http.NotFound(w, req)
+}
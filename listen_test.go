@@ -0,0 +1,104 @@
+package chi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	s := newTestServer()
+	s.GET("/api/users", func(c *Context) { c.String(http.StatusOK, "users") })
+	s.GET("/healthz", func(c *Context) { c.String(http.StatusOK, "ok") })
+	s.GET("/healthz-internal", func(c *Context) { c.String(http.StatusOK, "internal") })
+
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	require.NoError(t, s.Listen("127.0.0.1:0", WithName("public")))
+	require.NoError(t, s.Listen("tcp://127.0.0.1:0", WithName("health"), WithPaths("/healthz")))
+	require.NoError(t, s.Listen("unix:"+socket, WithName("admin")))
+	assert.Error(t, s.Listen("fd:abc"))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 3 }, time.Second, 10*time.Millisecond)
+	addrs := s.Addrs()
+
+	get := func(client *http.Client, url string) (int, string) {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get(http.DefaultClient, "http://"+addrs[0].String()+"/api/users")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "users", body)
+
+	code, _ = get(http.DefaultClient, "http://"+addrs[1].String()+"/api/users")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, body = get(http.DefaultClient, "http://"+addrs[1].String()+"/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body)
+	// 按路径段匹配，不放行同前缀的其他路径
+	code, _ = get(http.DefaultClient, "http://"+addrs[1].String()+"/healthz-internal")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	code, body = get(unixClient, "http://unix/api/users")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "users", body)
+
+	s.quit <- syscall.SIGTERM
+	assert.NoError(t, <-errCh)
+	_, err := net.Dial("tcp", addrs[0].String())
+	assert.Error(t, err)
+}
+
+func TestListenBindFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	s := newTestServer()
+	require.NoError(t, s.Listen("127.0.0.1:0"))
+	require.NoError(t, s.Listen(ln.Addr().String(), WithName("taken")))
+	assert.ErrorContains(t, s.Serve(), "failed to listen on taken")
+}
+
+func TestListenPathsWithHandler(t *testing.T) {
+	admin := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "admin") })
+	// 选项顺序不影响结果
+	for _, opts := range [][]ListenOption{
+		{WithPaths("/admin"), WithHandler(admin)},
+		{WithHandler(admin), WithPaths("/admin")},
+	} {
+		s := newTestServer()
+		require.NoError(t, s.Listen("127.0.0.1:0", opts...))
+		errCh := make(chan error, 1)
+		go func() { errCh <- s.Serve() }()
+		require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+
+		base := "http://" + s.Addrs()[0].String()
+		for path, want := range map[string]int{"/admin": http.StatusOK, "/admin/users": http.StatusOK, "/administrator": http.StatusNotFound} {
+			resp, err := http.Get(base + path)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, want, resp.StatusCode, path)
+		}
+		s.quit <- syscall.SIGTERM
+		assert.NoError(t, <-errCh)
+	}
+}
@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"chi"
+	"chi/pkg/logger"
+)
+
+// AccessLogConfig 访问日志中间件配置
+type AccessLogConfig struct {
+	// Logger 日志记录器，为nil时使用pkg/logger的全局日志记录器
+	Logger *logger.Logger
+	// SkipPaths 不记录的路径，匹配原始路径或路由模板，如健康检查和指标端点
+	SkipPaths []string
+	// SampleRate 正常请求的采样比例，0到1之间，为0时记录所有请求；错误请求和慢请求总是记录
+	SampleRate float64
+	// SlowThreshold 慢请求阈值，超过时以Warn级别记录，为0时不检测
+	SlowThreshold time.Duration
+	// Message 日志消息
+	Message string
+}
+
+// DefaultAccessLogConfig 默认访问日志配置
+var DefaultAccessLogConfig = AccessLogConfig{
+	SkipPaths:     []string{"/healthz", "/readyz", "/livez", "/metrics"},
+	SampleRate:    1,
+	SlowThreshold: time.Second,
+	Message:       "access",
+}
+
+// AccessLog 创建访问日志中间件
+// 使用默认配置的访问日志中间件
+func AccessLog() chi.MiddlewareFunc {
+	return AccessLogWithConfig(DefaultAccessLogConfig)
+}
+
+// AccessLogWithConfig 使用自定义配置创建访问日志中间件
+// 请求开始时将附带请求ID和链路追踪字段的日志记录器放入chi.LoggerKey，处理函数通过Context.Logger获取；
+// 请求结束后记录方法、路由模板、状态码、耗时、响应字节数、客户端IP、用户ID和请求ID。
+// 5xx以Error级别记录，4xx和慢请求以Warn级别记录，其余以Info级别记录。
+// 应放在RequestID之后、认证中间件之前
+// config: 访问日志配置参数
+// 返回值: 配置好的访问日志中间件函数
+func AccessLogWithConfig(config AccessLogConfig) chi.MiddlewareFunc {
+	if config.SampleRate <= 0 {
+		config.SampleRate = 1
+	}
+	if config.Message == "" {
+		config.Message = DefaultAccessLogConfig.Message
+	}
+	skip := make(map[string]struct{}, len(config.SkipPaths))
+	for _, p := range config.SkipPaths {
+		skip[p] = struct{}{}
+	}
+
+	return func(c *chi.Context) {
+		base := config.Logger
+		if base == nil {
+			base = logger.GetGlobal()
+		}
+		chi.SetValue(c, chi.LoggerKey, base.WithContext(c.Std()))
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		path := c.Request().URL.Path
+		route := c.FullPath()
+		if _, ok := skip[path]; ok {
+			return
+		}
+		if _, ok := skip[route]; ok && route != "" {
+			return
+		}
+
+		status := c.Writer().Status()
+		slow := config.SlowThreshold > 0 && latency >= config.SlowThreshold
+		if status < http.StatusBadRequest && !slow && config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+			return
+		}
+
+		fields := []logger.Field{
+			logger.String("method", c.Request().Method),
+			logger.String("route", route),
+			logger.String("path", path),
+			logger.Int("status", status),
+			logger.Duration("latency", latency),
+			logger.Int("bytes", max(c.Writer().Size(), 0)),
+			logger.String("client_ip", c.ClientIP()),
+		}
+		if slow {
+			fields = append(fields, logger.Bool("slow", true))
+		}
+		if errs := c.Context.Errors; len(errs) > 0 {
+			fields = append(fields, logger.String("errors", errs.String()))
+		}
+
+		// 认证中间件在请求处理过程中设置的用户ID也会被记录
+		l := base.WithContext(c.Std())
+		switch {
+		case status >= http.StatusInternalServerError:
+			l.Error(config.Message, fields...)
+		case status >= http.StatusBadRequest || slow:
+			l.Warn(config.Message, fields...)
+		default:
+			l.Info(config.Message, fields...)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"chi"
+)
+
+// CSRFMode CSRF令牌的保存方式
+type CSRFMode int
+
+const (
+	// CSRFDoubleSubmit 双重提交Cookie，令牌保存在Cookie中，请求时通过请求头或表单再提交一次，无需服务端状态；
+	// 令牌使用Secret签名并绑定到当前用户（chi.UserIDKey）或已保存的会话ID，
+	// 能够写入Cookie的攻击者（如控制了子域名）无法把自己获取的令牌用于其他用户。
+	// 匿名且没有会话的请求令牌不绑定身份，此时仅依赖来源检查和SameSite；
+	// 绑定的身份变化（登录、退出、Regenerate）后旧令牌失效，页面需要重新获取令牌
+	CSRFDoubleSubmit CSRFMode = iota
+	// CSRFSynchronizer 同步令牌，令牌保存在会话中，需要先使用Session中间件
+	CSRFSynchronizer
+)
+
+// CSRF校验失败的原因，通过ErrorHandler的err参数传入
+var (
+	// ErrCSRFTokenMissing 请求中没有CSRF令牌
+	ErrCSRFTokenMissing = errors.New("csrf token missing")
+	// ErrCSRFTokenInvalid CSRF令牌不匹配
+	ErrCSRFTokenInvalid = errors.New("csrf token invalid")
+	// ErrCSRFOrigin 请求来源不可信
+	ErrCSRFOrigin = errors.New("csrf origin not allowed")
+	// ErrCSRFNoSession 同步令牌模式下没有会话
+	ErrCSRFNoSession = errors.New("csrf requires session middleware")
+)
+
+// CSRFTokenKey 当前请求的CSRF令牌，通过CSRFToken或CSRFField获取
+var CSRFTokenKey = chi.NewKey[string]("csrf_token")
+
+// CSRFConfig CSRF中间件配置
+type CSRFConfig struct {
+	// Mode 令牌保存方式
+	Mode CSRFMode
+	// Secret 双重提交模式下签名令牌的密钥，至少32字节，多个副本需要使用相同的密钥
+	Secret []byte
+	// TokenLookup 提交令牌的来源，按顺序查找，格式同JWTConfig.TokenLookup；
+	// 默认的X-CSRF-Token请求头已包含在DefaultCORSConfig.AllowHeaders中
+	TokenLookup string
+	// FieldName CSRFField生成的隐藏表单字段名称，应与TokenLookup中的form来源一致
+	FieldName string
+	// CookieName 双重提交模式下保存令牌的Cookie名称
+	CookieName string
+	// CookiePath Cookie路径
+	CookiePath string
+	// CookieDomain Cookie域名
+	CookieDomain string
+	// CookieMaxAge Cookie有效期
+	CookieMaxAge time.Duration
+	// CookieSecure 是否只通过HTTPS发送Cookie，SameSite为None时强制启用
+	CookieSecure bool
+	// CookieHttpOnly 是否禁止JavaScript读取Cookie，前端需要从Cookie读取令牌时保持false
+	CookieHttpOnly bool
+	// CookieSameSite Cookie的SameSite属性
+	CookieSameSite http.SameSite
+	// SessionKey 同步令牌模式下令牌在会话中的键
+	SessionKey string
+	// TrustedOrigins 除当前主机外允许的来源，如"https://app.example.com"
+	TrustedOrigins []string
+	// ExemptPaths 不校验的路径，匹配原始路径或路由模板，以*结尾时按前缀匹配，如第三方回调
+	ExemptPaths []string
+	// Skipper 返回true时跳过校验
+	Skipper func(c *chi.Context) bool
+	// ErrorHandler 校验失败时的处理函数，默认返回chi.ErrForbidden
+	ErrorHandler func(c *chi.Context, err error)
+}
+
+// DefaultCSRFConfig 默认CSRF配置
+var DefaultCSRFConfig = CSRFConfig{
+	Mode:           CSRFDoubleSubmit,
+	TokenLookup:    "header:X-CSRF-Token,form:_csrf",
+	FieldName:      "_csrf",
+	CookieName:     "_csrf",
+	CookiePath:     "/",
+	CookieMaxAge:   12 * time.Hour,
+	CookieSameSite: http.SameSiteLaxMode,
+	SessionKey:     "_csrf_token",
+	ErrorHandler:   defaultCSRFErrorHandler,
+}
+
+// defaultCSRFErrorHandler 默认CSRF校验失败处理函数
+func defaultCSRFErrorHandler(c *chi.Context, err error) {
+	chi.FailRes(c, chi.ErrForbidden.Wrap(err))
+}
+
+// CSRF 创建CSRF防护中间件
+// 所有请求都会确保存在令牌并写入CSRFTokenKey；GET、HEAD、OPTIONS、TRACE以外的请求
+// 先检查Origin或Referer的协议和主机是否与当前请求或可信来源一致，再比较提交的令牌；
+// 双重提交模式应放在认证和Session中间件之后，以便令牌绑定到用户或会话
+// config: CSRF配置参数，通常复制DefaultCSRFConfig后修改；双重提交模式下Secret必填
+// 返回值: 配置好的CSRF中间件函数
+func CSRF(config CSRFConfig) chi.MiddlewareFunc {
+	if config.Mode == CSRFDoubleSubmit && len(config.Secret) < 32 {
+		panic("middlewares: CSRF double-submit mode requires a Secret of at least 32 bytes")
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultCSRFConfig.TokenLookup
+	}
+	if config.FieldName == "" {
+		config.FieldName = DefaultCSRFConfig.FieldName
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultCSRFConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultCSRFConfig.CookiePath
+	}
+	if config.CookieMaxAge <= 0 {
+		config.CookieMaxAge = DefaultCSRFConfig.CookieMaxAge
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultCSRFConfig.CookieSameSite
+	}
+	if config.CookieSameSite == http.SameSiteNoneMode {
+		// 浏览器拒绝非Secure的SameSite=None Cookie
+		config.CookieSecure = true
+	}
+	if config.SessionKey == "" {
+		config.SessionKey = DefaultCSRFConfig.SessionKey
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultCSRFConfig.ErrorHandler
+	}
+	sources := parseTokenLookup(config.TokenLookup)
+	trusted := make(map[string]struct{}, len(config.TrustedOrigins))
+	for _, o := range config.TrustedOrigins {
+		trusted[strings.ToLower(strings.TrimSuffix(o, "/"))] = struct{}{}
+	}
+
+	fail := func(c *chi.Context, err error) {
+		config.ErrorHandler(c, err)
+		c.Abort()
+	}
+
+	return func(c *chi.Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+		if csrfExempt(c, config.ExemptPaths) {
+			c.Next()
+			return
+		}
+
+		token, err := config.token(c)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		chi.SetValue(c, CSRFTokenKey, token)
+		chi.SetValue(c, csrfFieldKey, config.FieldName)
+
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+
+		if !csrfOriginAllowed(c, trusted) {
+			fail(c, ErrCSRFOrigin)
+			return
+		}
+		submitted := extractToken(c, sources, "")
+		if submitted == "" {
+			fail(c, ErrCSRFTokenMissing)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+			fail(c, ErrCSRFTokenInvalid)
+			return
+		}
+		c.Next()
+	}
+}
+
+// csrfFieldKey CSRFField使用的表单字段名称
+var csrfFieldKey = chi.NewKey[string]("csrf_field")
+
+// token 获取当前请求的令牌，不存在时生成新令牌
+func (config *CSRFConfig) token(c *chi.Context) (string, error) {
+	if config.Mode == CSRFSynchronizer {
+		sess := c.Session()
+		if sess == nil {
+			return "", ErrCSRFNoSession
+		}
+		if token, ok := chi.SessionValue[string](c, config.SessionKey); ok && token != "" {
+			return token, nil
+		}
+		token := newCSRFToken()
+		sess.Set(config.SessionKey, token)
+		return token, nil
+	}
+
+	// 签名无效或绑定的身份不一致的Cookie视为没有令牌，重新生成
+	binding := csrfBinding(c)
+	if token, err := c.Cookie(config.CookieName); err == nil && config.verify(token, binding) {
+		return token, nil
+	}
+	token := config.sign(newCSRFToken(), binding)
+	http.SetCookie(c.Writer(), &http.Cookie{
+		Name:     config.CookieName,
+		Value:    token,
+		Path:     config.CookiePath,
+		Domain:   config.CookieDomain,
+		MaxAge:   int(config.CookieMaxAge / time.Second),
+		Secure:   config.CookieSecure,
+		HttpOnly: config.CookieHttpOnly,
+		SameSite: config.CookieSameSite,
+	})
+	return token, nil
+}
+
+// csrfBinding 获取令牌绑定的客户端身份
+// 优先使用用户ID，其次使用已保存的会话ID；新会话的ID在保存前每次请求都会变化，不能用于绑定
+func csrfBinding(c *chi.Context) string {
+	if userID, ok := chi.GetValue(c, chi.UserIDKey); ok && userID != "" {
+		return "user:" + userID
+	}
+	if sess := c.Session(); sess != nil && !sess.IsNew() {
+		return "session:" + sess.ID()
+	}
+	return ""
+}
+
+// sign 为令牌附加签名，格式为"令牌.签名"，签名覆盖令牌和绑定的身份
+func (config *CSRFConfig) sign(token, binding string) string {
+	mac := hmac.New(sha256.New, config.Secret)
+	mac.Write([]byte(token))
+	mac.Write([]byte{0})
+	mac.Write([]byte(binding))
+	return token + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验令牌签名及绑定的身份
+func (config *CSRFConfig) verify(signed, binding string) bool {
+	i := strings.LastIndexByte(signed, '.')
+	if i <= 0 {
+		return false
+	}
+	return hmac.Equal([]byte(signed), []byte(config.sign(signed[:i], binding)))
+}
+
+// csrfExempt 判断请求是否在豁免列表中
+func csrfExempt(c *chi.Context, paths []string) bool {
+	path := c.Request().URL.Path
+	route := c.FullPath()
+	for _, p := range paths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if p == path || (route != "" && p == route) {
+			return true
+		}
+	}
+	return false
+}
+
+// csrfOriginAllowed 检查Origin或Referer的协议和主机是否与当前请求或可信来源一致
+// 两者都不存在时交由令牌校验
+func csrfOriginAllowed(c *chi.Context, trusted map[string]struct{}) bool {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		referer := c.GetHeader("Referer")
+		if referer == "" {
+			return true
+		}
+		origin = referer
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		// 包括隐私模式下的"null"
+		return false
+	}
+	origin = strings.ToLower(u.Scheme + "://" + u.Host)
+	if origin == strings.ToLower(requestScheme(c)+"://"+c.Request().Host) {
+		return true
+	}
+	_, ok := trusted[origin]
+	return ok
+}
+
+// requestScheme 获取请求的协议
+// TLS终止在反向代理时使用代理设置的X-Forwarded-Proto；浏览器跨站请求无法自行设置该请求头
+func requestScheme(c *chi.Context) string {
+	if c.Request().TLS != nil {
+		return "https"
+	}
+	if proto, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Proto"), ","); proto != "" {
+		return strings.TrimSpace(proto)
+	}
+	return "http"
+}
+
+// newCSRFToken 生成随机令牌
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// =============================================================================
+// 模板辅助
+// =============================================================================
+
+// CSRFToken 获取当前请求的CSRF令牌
+// c: 请求上下文
+// 返回值: 令牌，未使用CSRF中间件时为空
+func CSRFToken(c *chi.Context) string {
+	token, _ := chi.GetValue(c, CSRFTokenKey)
+	return token
+}
+
+// CSRFField 生成包含CSRF令牌的隐藏表单字段，传给HTML模板直接输出
+// 例如c.HTML(http.StatusOK, "form.html", gin.H{"csrfField": middlewares.CSRFField(c)})，模板中使用{{ .csrfField }}
+// c: 请求上下文
+// 返回值: 隐藏表单字段，未使用CSRF中间件时为空
+func CSRFField(c *chi.Context) template.HTML {
+	token := CSRFToken(c)
+	if token == "" {
+		return ""
+	}
+	name, _ := chi.GetValue(c, csrfFieldKey)
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(name) +
+		`" value="` + template.HTMLEscapeString(token) + `">`)
+}
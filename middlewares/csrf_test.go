@@ -0,0 +1,242 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"chi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCSRFSecret 测试使用的签名密钥
+var testCSRFSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newCSRFServer 创建注册了表单路由的测试服务器
+func newCSRFServer(middlewares ...chi.MiddlewareFunc) *chi.Server {
+	s := newTestServer(middlewares...)
+	s.GET("/form", func(c *chi.Context) {
+		c.String(http.StatusOK, CSRFToken(c))
+	})
+	s.POST("/form", func(c *chi.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	s.POST("/webhooks/pay", func(c *chi.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return s
+}
+
+// csrfCookie 获取响应中的CSRF Cookie
+func csrfCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == DefaultCSRFConfig.CookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// csrfPost 创建携带Cookie和令牌请求头的POST请求
+func csrfPost(path string, cookies []*http.Cookie, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	if token != "" {
+		req.Header.Set("X-CSRF-Token", token)
+	}
+	return req
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	cfg := DefaultCSRFConfig
+	cfg.Secret = testCSRFSecret
+	cfg.TrustedOrigins = []string{"https://app.example.com"}
+	cfg.ExemptPaths = []string{"/webhooks/*"}
+	s := newCSRFServer(CSRF(cfg))
+
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/form", nil))
+	cookie := csrfCookie(w)
+	require.NotNil(t, cookie)
+	token := w.Body.String()
+	assert.Equal(t, cookie.Value, token)
+	cookies := []*http.Cookie{cookie}
+
+	assert.Equal(t, http.StatusNoContent, serve(s, csrfPost("/form", cookies, token)).Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", cookies, "")).Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", cookies, token+"x")).Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", nil, token)).Code)
+	assert.Equal(t, http.StatusNoContent, serve(s, csrfPost("/webhooks/pay", nil, "")).Code)
+
+	// 已有令牌时不重新生成
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.AddCookie(cookie)
+	w = serve(s, req)
+	assert.Equal(t, token, w.Body.String())
+	assert.Nil(t, csrfCookie(w))
+
+	// 表单字段
+	form := url.Values{"_csrf": {token}}
+	req = httptest.NewRequest(http.MethodPost, "/form", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	assert.Equal(t, http.StatusNoContent, serve(s, req).Code)
+
+	// 来源检查
+	req = csrfPost("/form", cookies, token)
+	req.Header.Set("Origin", "https://evil.com")
+	assert.Equal(t, http.StatusForbidden, serve(s, req).Code)
+	req = csrfPost("/form", cookies, token)
+	req.Header.Set("Origin", "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, serve(s, req).Code)
+	req = csrfPost("/form", cookies, token)
+	req.Header.Set("Referer", "http://example.com/form")
+	assert.Equal(t, http.StatusNoContent, serve(s, req).Code)
+}
+
+func TestCSRFDoubleSubmitForgedCookie(t *testing.T) {
+	cfg := DefaultCSRFConfig
+	cfg.Secret = testCSRFSecret
+	s := newCSRFServer(CSRF(cfg))
+
+	// 攻击者写入的未签名令牌
+	forged := &http.Cookie{Name: cfg.CookieName, Value: "attacker-token"}
+	w := serve(s, csrfPost("/form", []*http.Cookie{forged}, forged.Value))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	require.NotNil(t, csrfCookie(w))
+	assert.NotEqual(t, forged.Value, csrfCookie(w).Value)
+
+	// 使用其他密钥签名的令牌
+	other := cfg
+	other.Secret = []byte("fedcba9876543210fedcba9876543210")
+	w = serve(newCSRFServer(CSRF(other)), httptest.NewRequest(http.MethodGet, "/form", nil))
+	forged = csrfCookie(w)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", []*http.Cookie{forged}, forged.Value)).Code)
+
+	assert.Panics(t, func() { CSRF(DefaultCSRFConfig) })
+}
+
+func TestCSRFSynchronizer(t *testing.T) {
+	store, err := NewCookieStore([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	sessionCfg := DefaultSessionConfig
+	sessionCfg.Store = store
+	cfg := DefaultCSRFConfig
+	cfg.Mode = CSRFSynchronizer
+	s := newCSRFServer(Session(sessionCfg), CSRF(cfg))
+
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/form", nil))
+	assert.Nil(t, csrfCookie(w))
+	session := sessionCookie(w)
+	require.NotNil(t, session)
+	token := w.Body.String()
+	require.NotEmpty(t, token)
+	cookies := []*http.Cookie{session}
+
+	assert.Equal(t, http.StatusNoContent, serve(s, csrfPost("/form", cookies, token)).Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", cookies, "other")).Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", cookies, "")).Code)
+	// 其他会话的令牌无效
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", nil, token)).Code)
+
+	// 没有Session中间件
+	s = newCSRFServer(CSRF(cfg))
+	assert.Equal(t, http.StatusForbidden, serve(s, httptest.NewRequest(http.MethodGet, "/form", nil)).Code)
+}
+
+func TestCSRFDoubleSubmitBinding(t *testing.T) {
+	cfg := DefaultCSRFConfig
+	cfg.Secret = testCSRFSecret
+	// 模拟认证中间件
+	auth := func(c *chi.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			chi.SetValue(c, chi.UserIDKey, user)
+		}
+		c.Next()
+	}
+	s := newCSRFServer(auth, CSRF(cfg))
+
+	// 攻击者获取自己的令牌并写入受害者的Cookie
+	w := serve(s, get(http.MethodGet, "/form", "X-User", "attacker"))
+	planted := csrfCookie(w)
+	require.NotNil(t, planted)
+	req := csrfPost("/form", []*http.Cookie{planted}, planted.Value)
+	req.Header.Set("X-User", "victim")
+	assert.Equal(t, http.StatusForbidden, serve(s, req).Code)
+
+	// 匿名令牌在登录后失效
+	w = serve(s, get(http.MethodGet, "/form"))
+	anonymous := csrfCookie(w)
+	assert.Equal(t, http.StatusNoContent, serve(s, csrfPost("/form", []*http.Cookie{anonymous}, anonymous.Value)).Code)
+	req = csrfPost("/form", []*http.Cookie{anonymous}, anonymous.Value)
+	req.Header.Set("X-User", "victim")
+	assert.Equal(t, http.StatusForbidden, serve(s, req).Code)
+
+	req = get(http.MethodGet, "/form", "X-User", "victim")
+	w = serve(s, req)
+	own := csrfCookie(w)
+	req = csrfPost("/form", []*http.Cookie{own}, own.Value)
+	req.Header.Set("X-User", "victim")
+	assert.Equal(t, http.StatusNoContent, serve(s, req).Code)
+
+	// 绑定到已保存的会话
+	store, err := NewCookieStore([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	sessionCfg := DefaultSessionConfig
+	sessionCfg.Store = store
+	s = newCSRFServer(Session(sessionCfg), CSRF(cfg))
+	s.GET("/start", func(c *chi.Context) {
+		c.Session().Set("started", true)
+		c.Status(http.StatusNoContent)
+	})
+	attacker := sessionCookie(serve(s, get(http.MethodGet, "/start")))
+	victim := sessionCookie(serve(s, get(http.MethodGet, "/start")))
+	w = serve(s, withCookie(http.MethodGet, "/form", attacker))
+	planted = csrfCookie(w)
+	assert.Equal(t, http.StatusNoContent, serve(s, csrfPost("/form", []*http.Cookie{attacker, planted}, planted.Value)).Code)
+	assert.Equal(t, http.StatusForbidden, serve(s, csrfPost("/form", []*http.Cookie{victim, planted}, planted.Value)).Code)
+}
+
+func TestCSRFOriginScheme(t *testing.T) {
+	cfg := DefaultCSRFConfig
+	cfg.Secret = testCSRFSecret
+	s := newCSRFServer(CSRF(cfg))
+	w := serve(s, get(http.MethodGet, "/form"))
+	cookies := []*http.Cookie{csrfCookie(w)}
+	token := w.Body.String()
+
+	tests := []struct {
+		name   string
+		origin string
+		tls    bool
+		proto  string
+		status int
+	}{
+		{"HTTP", "http://example.com", false, "", http.StatusNoContent},
+		{"HTTPOriginOnHTTPS", "http://example.com", true, "", http.StatusForbidden},
+		{"HTTPS", "https://example.com", true, "", http.StatusNoContent},
+		{"HTTPSOriginOnHTTP", "https://example.com", false, "", http.StatusForbidden},
+		{"ForwardedProto", "https://example.com", false, "https", http.StatusNoContent},
+		{"HTTPOriginForwardedHTTPS", "http://example.com", false, "https", http.StatusForbidden},
+		{"OtherHost", "https://evil.com", true, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := csrfPost("/form", cookies, token)
+			req.Header.Set("Origin", tt.origin)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			assert.Equal(t, tt.status, serve(s, req).Code)
+		})
+	}
+}
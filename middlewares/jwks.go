@@ -0,0 +1,251 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JWKSConfig JWKS密钥集配置
+type JWKSConfig struct {
+	// URL JWKS地址，如"https://auth.example.com/.well-known/jwks.json"
+	URL string
+	// RefreshInterval 定期刷新间隔，过期后下一次验证时重新获取
+	RefreshInterval time.Duration
+	// MinRefreshInterval 遇到未知kid时强制刷新的最小间隔，防止伪造kid导致频繁请求
+	MinRefreshInterval time.Duration
+	// Client 获取JWKS使用的HTTP客户端
+	Client *http.Client
+}
+
+// DefaultJWKSConfig 默认JWKS配置
+var DefaultJWKSConfig = JWKSConfig{
+	RefreshInterval:    time.Hour,
+	MinRefreshInterval: 10 * time.Second,
+	Client:             &http.Client{Timeout: 10 * time.Second},
+}
+
+// JWKS 缓存的远程JWKS密钥集
+// 密钥按kid索引，定期刷新；遇到未知kid时立即刷新以支持密钥轮换，
+// 刷新失败时继续使用已缓存的密钥；对称密钥（kty为oct）返回[]byte，
+// JWT中间件只有在Algorithms中显式启用HS算法时才接受
+type JWKS struct {
+	config JWKSConfig
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	// refreshMu 保证同一时间只有一个刷新请求
+	refreshMu sync.Mutex
+}
+
+// jwk JSON Web Key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// NewJWKS 创建JWKS密钥集，首次使用时获取
+// config: JWKS配置参数，URL必填
+// 返回值: JWKS密钥集
+func NewJWKS(config JWKSConfig) *JWKS {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultJWKSConfig.RefreshInterval
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = DefaultJWKSConfig.MinRefreshInterval
+	}
+	if config.Client == nil {
+		config.Client = DefaultJWKSConfig.Client
+	}
+	return &JWKS{config: config}
+}
+
+// Key 获取kid对应的验证密钥
+// 缓存过期或kid未知时刷新密钥集，两次刷新至少间隔MinRefreshInterval；
+// 并发请求只有一个发起刷新，缓存过期但仍有密钥的请求不等待刷新，直接使用已缓存的密钥
+// ctx: 上下文
+// kid: 密钥ID，为空时密钥集中只有一个密钥才能匹配
+// 返回值: 验证密钥和错误信息
+func (j *JWKS) Key(ctx context.Context, kid string) (interface{}, error) {
+	key, fresh := j.lookup(kid)
+	if key != nil && fresh {
+		return key, nil
+	}
+
+	if key != nil {
+		// 已有其他请求在刷新时继续使用过期的密钥
+		if !j.refreshMu.TryLock() {
+			return key, nil
+		}
+	} else {
+		j.refreshMu.Lock()
+	}
+	defer j.refreshMu.Unlock()
+
+	// 等待期间其他请求可能已经完成刷新
+	key, fresh = j.lookup(kid)
+	if key != nil && fresh {
+		return key, nil
+	}
+	j.mu.RLock()
+	canRefresh := time.Since(j.lastAttempt) >= j.config.MinRefreshInterval
+	j.mu.RUnlock()
+	if canRefresh {
+		if err := j.refresh(ctx); err != nil && key == nil {
+			return nil, err
+		}
+		key, _ = j.lookup(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("jwks: key %q not found", kid)
+	}
+	return key, nil
+}
+
+// lookup 在缓存中查找密钥
+// 返回值: 密钥（不存在时为nil）和缓存是否未过期
+func (j *JWKS) lookup(kid string) (interface{}, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	fresh := !j.fetchedAt.IsZero() && time.Since(j.fetchedAt) < j.config.RefreshInterval
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, fresh
+		}
+	}
+	return j.keys[kid], fresh
+}
+
+// Refresh 立即重新获取密钥集
+// ctx: 上下文
+// 返回值: 获取或解析失败时的错误信息，失败时保留已缓存的密钥
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+	return j.refresh(ctx)
+}
+
+// refresh 获取密钥集，调用方需持有refreshMu
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.lastAttempt = time.Now()
+	j.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("jwks: failed to create request: %w", err)
+	}
+	resp, err := j.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwks: failed to fetch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("jwks: failed to decode: %w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// 忽略不支持的密钥类型，不影响其他密钥
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
+
+// publicKey 将JWK转换为验证密钥
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt 解码base64url编码的大整数
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
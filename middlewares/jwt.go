@@ -0,0 +1,253 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"chi"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig JWT认证中间件配置
+type JWTConfig struct {
+	// Key 静态验证密钥，token没有kid或kid不在Keys中时使用
+	// HS算法为[]byte，RS/PS算法为*rsa.PublicKey，ES算法为*ecdsa.PublicKey，EdDSA为ed25519.PublicKey
+	Key interface{}
+	// Keys 按kid索引的静态验证密钥，用于密钥轮换期间同时接受新旧密钥
+	Keys map[string]interface{}
+	// JWKS 远程JWKS密钥集，设置后优先于静态密钥，
+	// 密钥集中没有匹配kid的密钥或获取失败时回退到Keys和Key，便于从静态密钥迁移到JWKS
+	JWKS *JWKS
+	// Algorithms 允许的签名算法，默认允许HS、RS、PS、ES系列和EdDSA；
+	// 设置JWKS时默认不允许HS算法，JWKS中的对称密钥只有显式启用HS算法时才会使用
+	Algorithms []string
+	// TokenLookup 令牌来源，按顺序查找，格式为"来源:名称"，来源支持header、cookie、query、form
+	TokenLookup string
+	// AuthScheme Authorization请求头中的认证方案
+	AuthScheme string
+	// Issuer 要求的签发者（iss），为空时不检查
+	Issuer string
+	// Audience 要求的受众（aud），为空时不检查
+	Audience string
+	// Leeway 校验exp、nbf、iat时允许的时钟偏差
+	Leeway time.Duration
+	// NewClaims 创建自定义claims实例，默认为*jwt.RegisteredClaims，通过JWTClaims获取
+	NewClaims func() jwt.Claims
+	// UserIDFunc 从claims中获取用户ID，写入chi.UserIDKey，默认使用sub
+	UserIDFunc func(claims jwt.Claims) string
+	// OptionalAuth 可选认证模式，没有令牌时以匿名身份继续处理，令牌无效时仍然拒绝
+	OptionalAuth bool
+	// ErrorHandler 认证失败时的处理函数，默认返回chi.ErrUnauthorized
+	ErrorHandler func(c *chi.Context, err error)
+}
+
+// jwksAlgorithms 使用JWKS且未设置Algorithms时允许的签名算法，不包含HS算法
+var jwksAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// DefaultJWTConfig 默认JWT配置
+var DefaultJWTConfig = JWTConfig{
+	Algorithms:   []string{"HS256", "HS384", "HS512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"},
+	TokenLookup:  "header:Authorization",
+	AuthScheme:   "Bearer",
+	NewClaims:    func() jwt.Claims { return &jwt.RegisteredClaims{} },
+	UserIDFunc:   defaultUserID,
+	ErrorHandler: defaultJWTErrorHandler,
+}
+
+// 认证结果在Context中的键
+var (
+	// JWTTokenKey 解析后的令牌
+	JWTTokenKey = chi.NewKey[*jwt.Token]("jwt_token")
+	// JWTClaimsKey 令牌的claims，类型为JWTConfig.NewClaims返回的类型
+	JWTClaimsKey = chi.NewKey[jwt.Claims]("jwt_claims")
+)
+
+// ErrMissingToken 请求中没有令牌
+var ErrMissingToken = errors.New("missing token")
+
+// tokenSource 令牌来源
+type tokenSource struct {
+	kind string
+	name string
+}
+
+// defaultUserID 使用sub作为用户ID
+func defaultUserID(claims jwt.Claims) string {
+	sub, _ := claims.GetSubject()
+	return sub
+}
+
+// defaultJWTErrorHandler 默认认证失败处理函数
+func defaultJWTErrorHandler(c *chi.Context, err error) {
+	chi.FailRes(c, chi.ErrUnauthorized.Wrap(err))
+}
+
+// JWT 创建JWT认证中间件
+// 验证通过后将令牌和claims写入JWTTokenKey、JWTClaimsKey，用户ID写入chi.UserIDKey，
+// 可以与RateLimitByUser(rate, burst, chi.UserIDKey.Name())配合按用户限流
+// config: JWT配置参数，Key、Keys、JWKS至少设置一个
+// 返回值: 配置好的JWT认证中间件函数
+func JWT(config JWTConfig) chi.MiddlewareFunc {
+	if len(config.Algorithms) == 0 {
+		config.Algorithms = DefaultJWTConfig.Algorithms
+		if config.JWKS != nil {
+			config.Algorithms = jwksAlgorithms
+		}
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultJWTConfig.TokenLookup
+	}
+	if config.AuthScheme == "" {
+		config.AuthScheme = DefaultJWTConfig.AuthScheme
+	}
+	if config.NewClaims == nil {
+		config.NewClaims = DefaultJWTConfig.NewClaims
+	}
+	if config.UserIDFunc == nil {
+		config.UserIDFunc = DefaultJWTConfig.UserIDFunc
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultJWTConfig.ErrorHandler
+	}
+	if config.Key == nil && len(config.Keys) == 0 && config.JWKS == nil {
+		panic("middlewares: JWT requires Key, Keys or JWKS")
+	}
+
+	sources := parseTokenLookup(config.TokenLookup)
+	opts := []jwt.ParserOption{jwt.WithValidMethods(config.Algorithms), jwt.WithLeeway(config.Leeway)}
+	if config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+	parser := jwt.NewParser(opts...)
+
+	return func(c *chi.Context) {
+		raw := extractToken(c, sources, config.AuthScheme)
+		if raw == "" {
+			if config.OptionalAuth {
+				c.Next()
+				return
+			}
+			config.ErrorHandler(c, ErrMissingToken)
+			c.Abort()
+			return
+		}
+
+		token, err := parser.ParseWithClaims(raw, config.NewClaims(), func(token *jwt.Token) (interface{}, error) {
+			return config.key(c, token)
+		})
+		if err != nil {
+			config.ErrorHandler(c, err)
+			c.Abort()
+			return
+		}
+
+		chi.SetValue(c, JWTTokenKey, token)
+		chi.SetValue(c, JWTClaimsKey, token.Claims)
+		if userID := config.UserIDFunc(token.Claims); userID != "" {
+			chi.SetValue(c, chi.UserIDKey, userID)
+		}
+		c.Next()
+	}
+}
+
+// key 根据令牌头中的kid查找验证密钥
+// 设置JWKS时优先使用JWKS中的密钥，找不到时回退到静态密钥
+func (config *JWTConfig) key(c *chi.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if config.JWKS != nil {
+		key, err := config.JWKS.Key(c.Request().Context(), kid)
+		if err != nil {
+			if key := config.staticKey(kid); key != nil {
+				return key, nil
+			}
+			return nil, err
+		}
+		if _, ok := key.([]byte); ok && !config.allowHMAC() {
+			return nil, fmt.Errorf("jwks: symmetric key %q requires an HS algorithm in Algorithms", kid)
+		}
+		return key, nil
+	}
+	if key := config.staticKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// staticKey 查找kid对应的静态密钥，kid不在Keys中时使用Key
+// 返回值: 密钥，没有可用的静态密钥时为nil
+func (config *JWTConfig) staticKey(kid string) interface{} {
+	if key, ok := config.Keys[kid]; ok && kid != "" {
+		return key
+	}
+	return config.Key
+}
+
+// allowHMAC 判断是否允许HS算法
+func (config *JWTConfig) allowHMAC() bool {
+	for _, alg := range config.Algorithms {
+		if strings.HasPrefix(alg, "HS") {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTClaims 获取JWT中间件写入的claims
+// c: 请求上下文
+// 返回值: 指定类型的claims（与JWTConfig.NewClaims返回的类型一致）以及是否存在
+func JWTClaims[T jwt.Claims](c *chi.Context) (T, bool) {
+	var zero T
+	claims, ok := chi.GetValue(c, JWTClaimsKey)
+	if !ok {
+		return zero, false
+	}
+	typed, ok := claims.(T)
+	return typed, ok
+}
+
+// parseTokenLookup 解析令牌来源配置
+func parseTokenLookup(lookup string) []tokenSource {
+	var sources []tokenSource
+	for _, part := range strings.Split(lookup, ",") {
+		kind, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || name == "" {
+			continue
+		}
+		sources = append(sources, tokenSource{kind: kind, name: name})
+	}
+	return sources
+}
+
+// extractToken 按顺序从请求中查找令牌
+func extractToken(c *chi.Context, sources []tokenSource, scheme string) string {
+	for _, src := range sources {
+		var value string
+		switch src.kind {
+		case "header":
+			value = c.GetHeader(src.name)
+			if value != "" && strings.EqualFold(src.name, "Authorization") {
+				prefix, token, ok := strings.Cut(value, " ")
+				if !ok || !strings.EqualFold(prefix, scheme) {
+					continue
+				}
+				value = strings.TrimSpace(token)
+			}
+		case "cookie":
+			value, _ = c.Cookie(src.name)
+		case "query":
+			value = c.Query(src.name)
+		case "form":
+			value = c.PostForm(src.name)
+		}
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,331 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer 创建使用真实HTTP状态码的测试服务器
+func newTestServer(middlewares ...chi.MiddlewareFunc) *chi.Server {
+	gin.SetMode(gin.TestMode)
+	s := chi.New()
+	s.UseHTTPStatus(true)
+	s.Use(middlewares...)
+	return s
+}
+
+// serve 发送请求并返回响应
+func serve(s *chi.Server, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+// whoami 返回JWT中间件写入的用户ID
+func whoami(c *chi.Context) {
+	userID, _ := chi.GetValue(c, chi.UserIDKey)
+	c.String(http.StatusOK, userID)
+}
+
+// signToken 签发测试令牌
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.Claims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	require.NoError(t, err)
+	return raw
+}
+
+// bearer 创建携带令牌的请求
+func bearer(path, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+// subject 创建指定sub的claims
+func subject(sub string) *jwt.RegisteredClaims {
+	return &jwt.RegisteredClaims{Subject: sub, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+}
+
+// b64 base64url编码
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// toJWK 将公钥转换为JWK
+func toJWK(t *testing.T, kid string, key interface{}) map[string]string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": kid, "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "x": b64(k.X.Bytes()), "y": b64(k.Y.Bytes())}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": b64(k)}
+	case []byte:
+		return map[string]string{"kty": "oct", "kid": kid, "k": b64(k)}
+	}
+	t.Fatalf("unsupported key %T", key)
+	return nil
+}
+
+// jwksServer 可修改密钥集并统计请求次数的JWKS服务
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches atomic.Int32
+	delay   time.Duration
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	js := &jwksServer{}
+	js.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		js.fetches.Add(1)
+		time.Sleep(js.delay)
+		js.mu.Lock()
+		defer js.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"keys": js.keys})
+	}))
+	t.Cleanup(js.Close)
+	return js
+}
+
+func (js *jwksServer) add(t *testing.T, kid string, key interface{}) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.keys = append(js.keys, toJWK(t, kid, key))
+}
+
+func TestJWTStaticKeys(t *testing.T) {
+	oldKey, newKey := []byte("old-secret"), []byte("new-secret")
+	s := newTestServer(JWT(JWTConfig{Key: oldKey, Keys: map[string]interface{}{"v2": newKey}}))
+	s.GET("/me", whoami)
+
+	w := serve(s, bearer("/me", signToken(t, jwt.SigningMethodHS256, oldKey, "", subject("u1"))))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "u1", w.Body.String())
+
+	w = serve(s, bearer("/me", signToken(t, jwt.SigningMethodHS256, newKey, "v2", subject("u2"))))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "u2", w.Body.String())
+
+	// 未知kid回退到Key
+	w = serve(s, bearer("/me", signToken(t, jwt.SigningMethodHS256, newKey, "v3", subject("u3"))))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJWTJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	js := newJWKSServer(t)
+	js.add(t, "rsa", &rsaKey.PublicKey)
+	js.add(t, "ec", &ecKey.PublicKey)
+	js.add(t, "ed", edPub)
+
+	s := newTestServer(JWT(JWTConfig{JWKS: NewJWKS(JWKSConfig{URL: js.URL, MinRefreshInterval: 100 * time.Millisecond})}))
+	s.GET("/me", whoami)
+
+	tokens := map[string]string{
+		"RS256": signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", subject("rs")),
+		"PS256": signToken(t, jwt.SigningMethodPS256, rsaKey, "rsa", subject("ps")),
+		"ES256": signToken(t, jwt.SigningMethodES256, ecKey, "ec", subject("es")),
+		"EdDSA": signToken(t, jwt.SigningMethodEdDSA, edKey, "ed", subject("ed")),
+	}
+	for alg, token := range tokens {
+		w := serve(s, bearer("/me", token))
+		assert.Equal(t, http.StatusOK, w.Code, alg)
+	}
+	assert.Equal(t, int32(1), js.fetches.Load())
+
+	// 密钥轮换：超过MinRefreshInterval后新kid触发一次刷新
+	time.Sleep(100 * time.Millisecond)
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	js.add(t, "rsa-2", &rotated.PublicKey)
+	w := serve(s, bearer("/me", signToken(t, jwt.SigningMethodRS256, rotated, "rsa-2", subject("rotated"))))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "rotated", w.Body.String())
+	assert.Equal(t, int32(2), js.fetches.Load())
+
+	// MinRefreshInterval内的未知kid不再请求JWKS
+	for range 3 {
+		w = serve(s, bearer("/me", signToken(t, jwt.SigningMethodRS256, rotated, "forged", subject("x"))))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	assert.Equal(t, int32(2), js.fetches.Load())
+
+	// 使用错误的密钥签名
+	w = serve(s, bearer("/me", signToken(t, jwt.SigningMethodRS256, rotated, "rsa", subject("x"))))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJWTJWKSSymmetricKey(t *testing.T) {
+	secret := []byte("shared-secret")
+	js := newJWKSServer(t)
+	js.add(t, "hs", secret)
+	token := signToken(t, jwt.SigningMethodHS256, secret, "hs", subject("u1"))
+
+	s := newTestServer(JWT(JWTConfig{JWKS: NewJWKS(JWKSConfig{URL: js.URL})}))
+	s.GET("/me", whoami)
+	assert.Equal(t, http.StatusUnauthorized, serve(s, bearer("/me", token)).Code)
+
+	s = newTestServer(JWT(JWTConfig{JWKS: NewJWKS(JWKSConfig{URL: js.URL}), Algorithms: []string{"HS256"}}))
+	s.GET("/me", whoami)
+	assert.Equal(t, http.StatusOK, serve(s, bearer("/me", token)).Code)
+}
+
+func TestJWTJWKSStaticFallback(t *testing.T) {
+	jwksKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	staticKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	legacyKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	js := newJWKSServer(t)
+	js.add(t, "jwks", &jwksKey.PublicKey)
+	// 密钥集只有一个密钥时没有kid的令牌会匹配该密钥
+	js.add(t, "jwks-2", &jwksKey.PublicKey)
+
+	s := newTestServer(JWT(JWTConfig{
+		JWKS: NewJWKS(JWKSConfig{URL: js.URL}),
+		Keys: map[string]interface{}{"static": &staticKey.PublicKey},
+		Key:  &legacyKey.PublicKey,
+	}))
+	s.GET("/me", whoami)
+
+	tests := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		kid    string
+		status int
+	}{
+		{"JWKS", jwksKey, "jwks", http.StatusOK},
+		{"Keys", staticKey, "static", http.StatusOK},
+		{"Key", legacyKey, "", http.StatusOK},
+		// JWKS中存在kid时不回退
+		{"JWKSWrongKey", legacyKey, "jwks", http.StatusUnauthorized},
+		{"UnknownKid", staticKey, "unknown", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := serve(s, bearer("/me", signToken(t, jwt.SigningMethodRS256, tt.key, tt.kid, subject("u1"))))
+		assert.Equal(t, tt.status, w.Code, tt.name)
+	}
+}
+
+func TestJWKSConcurrentRefresh(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	js := newJWKSServer(t)
+	js.add(t, "rsa", &key.PublicKey)
+	js.delay = 50 * time.Millisecond
+
+	jwks := NewJWKS(JWKSConfig{URL: js.URL, RefreshInterval: time.Hour})
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := jwks.Key(context.Background(), "rsa")
+			assert.NoError(t, err)
+			assert.NotNil(t, got)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), js.fetches.Load())
+}
+
+func TestJWTClaimsValidation(t *testing.T) {
+	secret := []byte("secret")
+	expired := &jwt.RegisteredClaims{
+		Subject:   "u1",
+		Issuer:    "auth",
+		Audience:  jwt.ClaimStrings{"api"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-30 * time.Second)),
+	}
+	token := signToken(t, jwt.SigningMethodHS256, secret, "", expired)
+
+	tests := []struct {
+		name   string
+		config JWTConfig
+		status int
+	}{
+		{"Expired", JWTConfig{Key: secret}, http.StatusUnauthorized},
+		{"Leeway", JWTConfig{Key: secret, Leeway: time.Minute, Issuer: "auth", Audience: "api"}, http.StatusOK},
+		{"WrongIssuer", JWTConfig{Key: secret, Leeway: time.Minute, Issuer: "other"}, http.StatusUnauthorized},
+		{"WrongAudience", JWTConfig{Key: secret, Leeway: time.Minute, Audience: "admin"}, http.StatusUnauthorized},
+		{"DisallowedAlgorithm", JWTConfig{Key: secret, Leeway: time.Minute, Algorithms: []string{"RS256"}}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(JWT(tt.config))
+			s.GET("/me", whoami)
+			assert.Equal(t, tt.status, serve(s, bearer("/me", token)).Code)
+		})
+	}
+}
+
+func TestJWTOptionalAuth(t *testing.T) {
+	secret := []byte("secret")
+	s := newTestServer(JWT(JWTConfig{Key: secret, OptionalAuth: true}))
+	s.GET("/me", whoami)
+
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/me", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = serve(s, bearer("/me", signToken(t, jwt.SigningMethodHS256, secret, "", subject("u1"))))
+	assert.Equal(t, "u1", w.Body.String())
+
+	w = serve(s, bearer("/me", "not-a-token"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJWTTokenLookup(t *testing.T) {
+	secret := []byte("secret")
+	token := signToken(t, jwt.SigningMethodHS256, secret, "", subject("u1"))
+	s := newTestServer(JWT(JWTConfig{Key: secret, TokenLookup: "header:Authorization,cookie:jwt,query:token"}))
+	s.GET("/me", whoami)
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+	assert.Equal(t, "u1", serve(s, req).Body.String())
+
+	assert.Equal(t, "u1", serve(s, httptest.NewRequest(http.MethodGet, "/me?token="+token, nil)).Body.String())
+	assert.Equal(t, "u1", serve(s, bearer("/me", token)).Body.String())
+
+	// 认证方案不匹配时视为没有令牌
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Basic "+token)
+	assert.Equal(t, http.StatusUnauthorized, serve(s, req).Code)
+}
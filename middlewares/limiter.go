@@ -0,0 +1,416 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Algorithm 限流算法
+type Algorithm string
+
+const (
+	// AlgorithmTokenBucket 令牌桶，按固定速率补充令牌，允许Burst个突发请求
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+	// AlgorithmSlidingLog 滑动窗口日志，记录窗口内每个请求的时间，精确但占用内存与Rate成正比
+	AlgorithmSlidingLog Algorithm = "sliding_log"
+	// AlgorithmSlidingWindow 滑动窗口计数，按上一窗口计数加权估算，内存占用固定
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+	// AlgorithmGCRA 通用信元速率算法，效果与令牌桶相同，只需保存一个时间戳
+	AlgorithmGCRA Algorithm = "gcra"
+)
+
+// Limit 限流规则
+type Limit struct {
+	// Name 规则名称，同时生效的多个规则通过名称区分计数，如"burst"、"daily"
+	Name string `json:"name" yaml:"name"`
+	// Rate 每个周期允许的请求数
+	Rate int `json:"rate" yaml:"rate"`
+	// Period 周期，默认1秒
+	Period time.Duration `json:"period" yaml:"period"`
+	// Burst 令牌桶和GCRA允许的突发请求数，默认等于Rate；滑动窗口算法忽略此项
+	Burst int `json:"burst" yaml:"burst"`
+	// Algorithm 该规则使用的算法，为空时使用限流器的算法；
+	// 长周期配额通常使用滑动窗口，避免令牌桶在周期内逐渐恢复额度
+	Algorithm Algorithm `json:"algorithm" yaml:"algorithm"`
+}
+
+// normalize 补全默认值
+func (l Limit) normalize() Limit {
+	if l.Period <= 0 {
+		l.Period = time.Second
+	}
+	if l.Rate <= 0 {
+		l.Rate = 1
+	}
+	if l.Burst <= 0 {
+		l.Burst = l.Rate
+	}
+	return l
+}
+
+// interval 补充一个令牌所需的时间（纳秒）
+// 使用浮点数避免Period不能被Rate整除时截断，与Redis脚本的计算方式一致
+func (l Limit) interval() float64 {
+	return float64(l.Period) / float64(l.Rate)
+}
+
+// checkInterval 检查补充间隔是否可以计时
+// 返回值: 速率超过周期的纳秒数（间隔不足1纳秒）时返回错误
+func (l Limit) checkInterval() error {
+	l = l.normalize()
+	if int64(l.Rate) > int64(l.Period) {
+		return fmt.Errorf("rate %d exceeds the resolution of period %s", l.Rate, l.Period)
+	}
+	return nil
+}
+
+// RateLimitResult 限流结果
+type RateLimitResult struct {
+	// Allowed 是否允许请求
+	Allowed bool
+	// Limit 允许的请求数，令牌桶和GCRA为Burst，滑动窗口为Rate
+	Limit int
+	// Remaining 剩余可用的请求数
+	Remaining int
+	// RetryAfter 被拒绝时距离下一次允许的时间
+	RetryAfter time.Duration
+	// ResetAfter 距离额度完全恢复的时间
+	ResetAfter time.Duration
+}
+
+// Limiter 限流器
+// 同一个限流器可以按不同的键和规则限流；多个中间件共享限流器时应使用不同的键前缀
+type Limiter interface {
+	// Allow 消耗键的一次请求额度
+	Allow(ctx context.Context, key string, limit Limit) (*RateLimitResult, error)
+}
+
+// LimitRefunder 可以退还额度的限流器
+// 多个规则同时生效时，请求被某个规则拒绝后通过Refund退还之前的规则已消耗的额度，
+// 避免被拒绝的请求占用其他规则的额度；MemoryLimiter和RedisLimiter都实现了该接口
+type LimitRefunder interface {
+	// Refund 退还键最近消耗的一次请求额度
+	Refund(ctx context.Context, key string, limit Limit) error
+}
+
+// valid 检查限流算法是否受支持，空值表示使用默认算法
+func (a Algorithm) valid() bool {
+	switch a {
+	case "", AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmGCRA:
+		return true
+	}
+	return false
+}
+
+// checkAlgorithm 检查限流算法是否受支持
+func checkAlgorithm(algorithm Algorithm) Algorithm {
+	if !algorithm.valid() {
+		panic(fmt.Sprintf("middlewares: unknown rate limit algorithm %q", algorithm))
+	}
+	if algorithm == "" {
+		return AlgorithmTokenBucket
+	}
+	return algorithm
+}
+
+// =============================================================================
+// 内存限流器
+// =============================================================================
+
+// MemoryLimiter 进程内限流器，多副本部署时每个副本单独计数
+type MemoryLimiter struct {
+	algorithm Algorithm
+	mu        sync.Mutex
+	states    map[string]*limiterState
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// limiterState 单个键的限流状态
+type limiterState struct {
+	// 令牌桶
+	tokens float64
+	last   time.Time
+	// 滑动窗口日志
+	log []time.Time
+	// 滑动窗口计数
+	window    int64
+	cur, prev int
+	// GCRA理论到达时间
+	tat time.Time
+
+	expires time.Time
+	// used 最近一次请求的时间
+	used time.Time
+}
+
+// sweepInterval 清理过期状态的间隔
+const sweepInterval = time.Minute
+
+// NewMemoryLimiter 创建内存限流器
+// algorithm: 限流算法，为空时使用令牌桶
+// 返回值: 内存限流器
+func NewMemoryLimiter(algorithm Algorithm) *MemoryLimiter {
+	return &MemoryLimiter{
+		algorithm: checkAlgorithm(algorithm),
+		states:    make(map[string]*limiterState),
+		now:       time.Now,
+	}
+}
+
+// Allow 实现Limiter接口
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (*RateLimitResult, error) {
+	limit = limit.normalize()
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	st, ok := l.states[key]
+	if !ok {
+		st = &limiterState{}
+		l.states[key] = st
+	}
+
+	var res *RateLimitResult
+	switch l.algorithmOf(limit) {
+	case AlgorithmSlidingLog:
+		res = st.slidingLog(now, limit)
+	case AlgorithmSlidingWindow:
+		res = st.slidingWindow(now, limit)
+	case AlgorithmGCRA:
+		res = st.gcra(now, limit)
+	default:
+		res = st.tokenBucket(now, limit)
+	}
+	st.expires = now.Add(max(2*limit.Period, time.Duration(float64(limit.Burst)*limit.interval())))
+	st.used = now
+	return res, nil
+}
+
+// Refund 实现LimitRefunder接口
+func (l *MemoryLimiter) Refund(ctx context.Context, key string, limit Limit) error {
+	limit = limit.normalize()
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st, ok := l.states[key]
+	if !ok {
+		return nil
+	}
+	switch l.algorithmOf(limit) {
+	case AlgorithmSlidingLog:
+		if n := len(st.log); n > 0 {
+			st.log = st.log[:n-1]
+		}
+	case AlgorithmSlidingWindow:
+		if st.window == now.UnixNano()/int64(limit.Period) && st.cur > 0 {
+			st.cur--
+		}
+	case AlgorithmGCRA:
+		st.tat = st.tat.Add(-time.Duration(math.Round(limit.interval())))
+	default:
+		st.tokens = math.Min(float64(limit.Burst), st.tokens+1)
+	}
+	return nil
+}
+
+// algorithmOf 获取规则使用的算法
+func (l *MemoryLimiter) algorithmOf(limit Limit) Algorithm {
+	if limit.Algorithm != "" {
+		return checkAlgorithm(limit.Algorithm)
+	}
+	return l.algorithm
+}
+
+// sweep 清理过期的状态，避免键无限增长
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, st := range l.states {
+		if now.After(st.expires) {
+			delete(l.states, key)
+		}
+	}
+}
+
+// tokenBucket 令牌桶算法
+func (st *limiterState) tokenBucket(now time.Time, limit Limit) *RateLimitResult {
+	interval := limit.interval()
+	burst := float64(limit.Burst)
+	if st.last.IsZero() {
+		st.tokens = burst
+	} else {
+		st.tokens = math.Min(burst, st.tokens+float64(now.Sub(st.last))/interval)
+	}
+	st.last = now
+
+	res := &RateLimitResult{Limit: limit.Burst}
+	if st.tokens >= 1 {
+		st.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - st.tokens) * interval))
+	}
+	res.Remaining = int(st.tokens)
+	res.ResetAfter = time.Duration(math.Ceil((burst - st.tokens) * interval))
+	return res
+}
+
+// slidingLog 滑动窗口日志算法
+func (st *limiterState) slidingLog(now time.Time, limit Limit) *RateLimitResult {
+	start := now.Add(-limit.Period)
+	i := 0
+	for i < len(st.log) && !st.log[i].After(start) {
+		i++
+	}
+	st.log = st.log[i:]
+
+	res := &RateLimitResult{Limit: limit.Rate}
+	if len(st.log) < limit.Rate {
+		st.log = append(st.log, now)
+		res.Allowed = true
+	} else {
+		res.RetryAfter = st.log[0].Add(limit.Period).Sub(now)
+	}
+	res.Remaining = limit.Rate - len(st.log)
+	res.ResetAfter = st.log[len(st.log)-1].Add(limit.Period).Sub(now)
+	return res
+}
+
+// slidingWindow 滑动窗口计数算法
+func (st *limiterState) slidingWindow(now time.Time, limit Limit) *RateLimitResult {
+	period := int64(limit.Period)
+	window := now.UnixNano() / period
+	switch {
+	case st.window == window:
+	case st.window == window-1:
+		st.prev, st.cur = st.cur, 0
+	default:
+		st.prev, st.cur = 0, 0
+	}
+	st.window = window
+
+	elapsed := now.UnixNano() - window*period
+	weight := float64(period-elapsed) / float64(period)
+	estimate := float64(st.prev)*weight + float64(st.cur)
+
+	res := &RateLimitResult{Limit: limit.Rate, ResetAfter: time.Duration(period - elapsed)}
+	if estimate+1 <= float64(limit.Rate) {
+		st.cur++
+		estimate++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = slidingWindowRetry(st.prev, st.cur, limit.Rate, period, elapsed)
+	}
+	res.Remaining = max(0, int(float64(limit.Rate)-estimate))
+	return res
+}
+
+// slidingWindowRetry 计算滑动窗口计数算法下一次允许的等待时间
+func slidingWindowRetry(prev, cur, rate int, period, elapsed int64) time.Duration {
+	free := rate - 1 - cur
+	if free >= 0 && prev > 0 {
+		// 上一窗口的权重降到free/prev以下即可
+		at := int64(math.Ceil(float64(period) * (1 - float64(free)/float64(prev))))
+		return time.Duration(max(at-elapsed, 1))
+	}
+	// 当前窗口已满，等到下一个窗口中当前计数的权重降到(rate-1)/cur以下
+	at := int64(math.Ceil(float64(period) * (1 - float64(rate-1)/float64(cur))))
+	return time.Duration(period - elapsed + max(at, 0))
+}
+
+// gcra 通用信元速率算法
+func (st *limiterState) gcra(now time.Time, limit Limit) *RateLimitResult {
+	interval := limit.interval()
+	tat := st.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(time.Duration(math.Round(interval)))
+	allowAt := tat.Add(-time.Duration(float64(limit.Burst-1) * interval))
+
+	res := &RateLimitResult{Limit: limit.Burst}
+	if now.Before(allowAt) {
+		res.RetryAfter = allowAt.Sub(now)
+		res.ResetAfter = tat.Sub(now)
+		return res
+	}
+	st.tat = newTat
+	res.Allowed = true
+	res.Remaining = int(float64(now.Sub(allowAt)) / interval)
+	res.ResetAfter = newTat.Sub(now)
+	return res
+}
+
+// =============================================================================
+// 旧版接口
+// =============================================================================
+
+// TokenBucket 单个令牌桶
+//
+// Deprecated: 使用NewMemoryLimiter创建的MemoryLimiter，支持多种算法且按键管理状态
+type TokenBucket struct {
+	limiter *MemoryLimiter
+	limit   Limit
+}
+
+// NewTokenBucket 创建新的令牌桶
+//
+// Deprecated: 使用NewMemoryLimiter(AlgorithmTokenBucket)和Limit{Rate: rate, Burst: capacity}
+func NewTokenBucket(rate, capacity int) *TokenBucket {
+	return &TokenBucket{
+		limiter: NewMemoryLimiter(AlgorithmTokenBucket),
+		limit:   Limit{Rate: rate, Burst: capacity},
+	}
+}
+
+// Allow 检查是否允许请求通过
+func (tb *TokenBucket) Allow() bool {
+	res, _ := tb.limiter.Allow(context.Background(), "", tb.limit)
+	return res.Allowed
+}
+
+// RateLimiter 按键限流的令牌桶管理器
+//
+// Deprecated: 使用NewMemoryLimiter创建的MemoryLimiter
+type RateLimiter struct {
+	limiter *MemoryLimiter
+	limit   Limit
+}
+
+// NewRateLimiter 创建新的限流器
+//
+// Deprecated: 使用NewMemoryLimiter(AlgorithmTokenBucket)和Limit{Rate: rate, Burst: burst}
+func NewRateLimiter(rate, burst int) *RateLimiter {
+	return &RateLimiter{
+		limiter: NewMemoryLimiter(AlgorithmTokenBucket),
+		limit:   Limit{Rate: rate, Burst: burst},
+	}
+}
+
+// Allow 检查指定键是否允许请求
+func (rl *RateLimiter) Allow(key string) bool {
+	res, _ := rl.limiter.Allow(context.Background(), key, rl.limit)
+	return res.Allowed
+}
+
+// Cleanup 清理超过maxAge没有请求的键，MemoryLimiter会自动清理过期状态，通常不需要调用
+func (rl *RateLimiter) Cleanup(maxAge time.Duration) {
+	l := rl.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for key, st := range l.states {
+		if now.Sub(st.used) > maxAge {
+			delete(l.states, key)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"chi/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// =============================================================================
+// Redis限流器
+// =============================================================================
+
+// 限流脚本，时间使用Redis服务器的TIME，避免各副本时钟不一致
+// 参数为规则的Rate、Period（微秒）、Burst，返回{是否允许, 剩余请求数, RetryAfter（微秒）, ResetAfter（微秒）}
+const luaNow = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local rate = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+`
+
+var limiterScripts = map[Algorithm]string{
+	AlgorithmTokenBucket: luaNow + `
+local interval = period / rate
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+else
+	tokens = math.min(burst, tokens + (now - ts) / interval)
+end
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * interval)
+end
+local reset = math.ceil((burst - tokens) * interval)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', string.format('%d', now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(math.max(reset, period) / 1000) + 1000)
+return {allowed, math.floor(tokens), retry, reset}
+`,
+	AlgorithmSlidingLog: luaNow + `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - period)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed, retry = 0, 0
+if count < rate then
+	redis.call('ZADD', KEYS[1], now, string.format('%d', now) .. '-' .. ARGV[4])
+	count = count + 1
+	allowed = 1
+else
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	retry = tonumber(oldest[2]) + period - now
+end
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+local reset = tonumber(newest[2]) + period - now
+redis.call('PEXPIRE', KEYS[1], math.ceil(period / 1000) + 1000)
+return {allowed, rate - count, retry, reset}
+`,
+	AlgorithmSlidingWindow: luaNow + `
+local window = math.floor(now / period)
+local state = redis.call('HMGET', KEYS[1], 'window', 'cur', 'prev')
+local w = tonumber(state[1])
+local cur = tonumber(state[2]) or 0
+local prev = tonumber(state[3]) or 0
+if w == window - 1 then
+	prev = cur
+	cur = 0
+elseif w ~= window then
+	prev = 0
+	cur = 0
+end
+local elapsed = now - window * period
+local estimate = prev * (period - elapsed) / period + cur
+local allowed, retry = 0, 0
+if estimate + 1 <= rate then
+	cur = cur + 1
+	estimate = estimate + 1
+	allowed = 1
+else
+	local free = rate - 1 - cur
+	if free >= 0 and prev > 0 then
+		retry = math.max(math.ceil(period * (1 - free / prev)) - elapsed, 1)
+	else
+		retry = period - elapsed + math.max(math.ceil(period * (1 - (rate - 1) / cur)), 0)
+	end
+end
+redis.call('HSET', KEYS[1], 'window', string.format('%d', window), 'cur', cur, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], math.ceil(2 * period / 1000) + 1000)
+return {allowed, math.max(0, math.floor(rate - estimate)), retry, period - elapsed}
+`,
+	AlgorithmGCRA: luaNow + `
+local interval = period / rate
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+tat = math.max(tat, now)
+local newTat = tat + interval
+local allowAt = newTat - burst * interval
+if now < allowAt then
+	return {0, 0, math.ceil(allowAt - now), tat - now}
+end
+redis.call('SET', KEYS[1], string.format('%d', math.ceil(newTat)), 'PX', math.ceil((newTat - now) / 1000) + 1000)
+return {1, math.floor((now - allowAt) / interval), 0, math.ceil(newTat - now)}
+`,
+}
+
+// 退还额度的脚本，参数与限流脚本相同
+var refundScripts = map[Algorithm]string{
+	AlgorithmTokenBucket: luaNow + `
+local tokens = tonumber(redis.call('HGET', KEYS[1], 'tokens'))
+if tokens ~= nil then
+	redis.call('HSET', KEYS[1], 'tokens', tostring(math.min(burst, tokens + 1)))
+end
+return 0
+`,
+	AlgorithmSlidingLog: luaNow + `
+redis.call('ZPOPMAX', KEYS[1])
+return 0
+`,
+	AlgorithmSlidingWindow: luaNow + `
+local window = math.floor(now / period)
+local state = redis.call('HMGET', KEYS[1], 'window', 'cur')
+if tonumber(state[1]) == window and (tonumber(state[2]) or 0) > 0 then
+	redis.call('HINCRBY', KEYS[1], 'cur', -1)
+end
+return 0
+`,
+	AlgorithmGCRA: luaNow + `
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat ~= nil then
+	tat = tat - period / rate
+	if tat > now then
+		redis.call('SET', KEYS[1], string.format('%d', math.ceil(tat)), 'PX', math.ceil((tat - now) / 1000) + 1000)
+	else
+		redis.call('DEL', KEYS[1])
+	end
+end
+return 0
+`,
+}
+
+// RedisLimiter 基于Redis的分布式限流器，所有副本共享计数
+// 每次限流通过一个Lua脚本原子完成，优先使用EvalSha，脚本未缓存时回退到Eval；需要Redis 5及以上
+type RedisLimiter struct {
+	client    *cache.Client
+	algorithm Algorithm
+	prefix    string
+}
+
+// 限流脚本和退还额度脚本的SHA1
+var (
+	limiterSHA = scriptSHA(limiterScripts)
+	refundSHA  = scriptSHA(refundScripts)
+)
+
+// scriptSHA 计算脚本的SHA1
+func scriptSHA(scripts map[Algorithm]string) map[Algorithm]string {
+	shas := make(map[Algorithm]string, len(scripts))
+	for algorithm, script := range scripts {
+		sum := sha1.Sum([]byte(script))
+		shas[algorithm] = hex.EncodeToString(sum[:])
+	}
+	return shas
+}
+
+// NewRedisLimiter 创建Redis限流器
+// client: pkg/cache客户端
+// algorithm: 限流算法，为空时使用令牌桶
+// prefix: 限流键前缀，为空时使用"ratelimit:"
+// 返回值: Redis限流器
+func NewRedisLimiter(client *cache.Client, algorithm Algorithm, prefix string) *RedisLimiter {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisLimiter{
+		client:    client,
+		algorithm: checkAlgorithm(algorithm),
+		prefix:    prefix,
+	}
+}
+
+// Allow 实现Limiter接口
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (*RateLimitResult, error) {
+	limit = limit.normalize()
+	algorithm := l.algorithmOf(limit)
+	args := []interface{}{limit.Rate, limit.Period.Microseconds(), limit.Burst}
+	if algorithm == AlgorithmSlidingLog {
+		// 同一微秒内的请求需要不同的成员
+		args = append(args, rand.Text())
+	}
+
+	reply, err := l.eval(ctx, limiterScripts[algorithm], limiterSHA[algorithm], l.key(algorithm, key), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+	n := make([]int64, len(values))
+	for i, v := range values {
+		if n[i], ok = v.(int64); !ok {
+			return nil, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+		}
+	}
+
+	res := &RateLimitResult{
+		Allowed:    n[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(n[1]),
+		RetryAfter: time.Duration(n[2]) * time.Microsecond,
+		ResetAfter: time.Duration(n[3]) * time.Microsecond,
+	}
+	if algorithm == AlgorithmSlidingLog || algorithm == AlgorithmSlidingWindow {
+		res.Limit = limit.Rate
+	}
+	return res, nil
+}
+
+// Refund 实现LimitRefunder接口
+func (l *RedisLimiter) Refund(ctx context.Context, key string, limit Limit) error {
+	limit = limit.normalize()
+	algorithm := l.algorithmOf(limit)
+	_, err := l.eval(ctx, refundScripts[algorithm], refundSHA[algorithm], l.key(algorithm, key),
+		limit.Rate, limit.Period.Microseconds(), limit.Burst)
+	if err != nil {
+		return fmt.Errorf("failed to run rate limit refund script: %w", err)
+	}
+	return nil
+}
+
+// algorithmOf 获取规则使用的算法
+func (l *RedisLimiter) algorithmOf(limit Limit) Algorithm {
+	if limit.Algorithm != "" {
+		return checkAlgorithm(limit.Algorithm)
+	}
+	return l.algorithm
+}
+
+// key 生成Redis键，不同算法的状态结构不同，使用不同的键
+func (l *RedisLimiter) key(algorithm Algorithm, key string) string {
+	return l.prefix + string(algorithm) + ":" + key
+}
+
+// eval 执行脚本，优先使用EvalSha，脚本未缓存时回退到Eval
+func (l *RedisLimiter) eval(ctx context.Context, script, sha, key string, args ...interface{}) (interface{}, error) {
+	keys := []string{key}
+	reply, err := l.client.EvalSha(ctx, sha, keys, args...)
+	if redis.HasErrorPrefix(err, "NOSCRIPT") {
+		reply, err = l.client.Eval(ctx, script, keys, args...)
+	}
+	return reply, err
+}
@@ -0,0 +1,255 @@
+package middlewares
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// algorithms 所有限流算法
+var algorithms = []Algorithm{AlgorithmTokenBucket, AlgorithmSlidingLog, AlgorithmSlidingWindow, AlgorithmGCRA}
+
+// testClock 可手动推进的时钟
+type testClock struct {
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestMemoryLimiter 创建使用测试时钟的内存限流器
+func newTestMemoryLimiter(algorithm Algorithm, clock *testClock) *MemoryLimiter {
+	l := NewMemoryLimiter(algorithm)
+	l.now = clock.Now
+	return l
+}
+
+// testLimiter 检查限流器用完额度后拒绝请求，等待RetryAfter后恢复
+func testLimiter(t *testing.T, l Limiter, advance func(time.Duration)) {
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Period: time.Second}
+	for i := range 2 {
+		res, err := l.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, i)
+		assert.Equal(t, 2, res.Limit)
+		assert.Equal(t, 1-i, res.Remaining)
+		assert.Positive(t, res.ResetAfter)
+	}
+
+	res, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Zero(t, res.Remaining)
+	require.Positive(t, res.RetryAfter)
+
+	// 其他键单独计数
+	other, err := l.Allow(ctx, "other", limit)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed)
+
+	advance(res.RetryAfter - time.Millisecond)
+	res2, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.False(t, res2.Allowed)
+
+	advance(time.Millisecond)
+	res, err = l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			clock := newTestClock()
+			testLimiter(t, newTestMemoryLimiter(algorithm, clock), clock.Add)
+		})
+	}
+}
+
+func TestMemoryLimiterRetryAfter(t *testing.T) {
+	tests := []struct {
+		algorithm Algorithm
+		retry     time.Duration
+	}{
+		{AlgorithmTokenBucket, 500 * time.Millisecond},
+		{AlgorithmGCRA, 500 * time.Millisecond},
+		{AlgorithmSlidingLog, time.Second},
+		{AlgorithmSlidingWindow, 1500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			l := newTestMemoryLimiter(tt.algorithm, newTestClock())
+			limit := Limit{Rate: 2, Period: time.Second}
+			var res *RateLimitResult
+			for range 3 {
+				res, _ = l.Allow(context.Background(), "k", limit)
+			}
+			assert.False(t, res.Allowed)
+			assert.Equal(t, tt.retry, res.RetryAfter)
+		})
+	}
+}
+
+func TestMemoryLimiterSubSecondRefill(t *testing.T) {
+	clock := newTestClock()
+	l := newTestMemoryLimiter(AlgorithmTokenBucket, clock)
+	limit := Limit{Rate: 10, Period: time.Second, Burst: 1}
+	ctx := context.Background()
+
+	res, _ := l.Allow(ctx, "k", limit)
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "k", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 100*time.Millisecond, res.RetryAfter)
+
+	// 每100ms补充一个令牌
+	for range 5 {
+		clock.Add(100 * time.Millisecond)
+		res, _ = l.Allow(ctx, "k", limit)
+		assert.True(t, res.Allowed)
+	}
+}
+
+func TestMemoryLimiterFractionalInterval(t *testing.T) {
+	clock := newTestClock()
+	l := newTestMemoryLimiter(AlgorithmTokenBucket, clock)
+	// 1秒不能被3整除，补充间隔为333333333.3ns
+	limit := Limit{Rate: 3, Period: time.Second, Burst: 1}
+	ctx := context.Background()
+
+	res, _ := l.Allow(ctx, "k", limit)
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "k", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 333333334*time.Nanosecond, res.RetryAfter)
+
+	clock.Add(333333333 * time.Nanosecond)
+	res, _ = l.Allow(ctx, "k", limit)
+	assert.False(t, res.Allowed)
+	clock.Add(time.Nanosecond)
+	res, _ = l.Allow(ctx, "k", limit)
+	assert.True(t, res.Allowed)
+
+	// 补充间隔不足1纳秒的规则无效
+	assert.NoError(t, Limit{Rate: 1e9, Period: time.Second}.checkInterval())
+	assert.Error(t, Limit{Rate: 2e9, Period: time.Second}.checkInterval())
+	assert.Error(t, Limit{Rate: 10, Period: 5}.checkInterval())
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	clock := newTestClock()
+	l := newTestMemoryLimiter(AlgorithmTokenBucket, clock)
+	l.Allow(context.Background(), "a", Limit{Rate: 1})
+	assert.Len(t, l.states, 1)
+
+	clock.Add(sweepInterval + time.Second)
+	l.Allow(context.Background(), "b", Limit{Rate: 1})
+	assert.Len(t, l.states, 1)
+	assert.Contains(t, l.states, "b")
+}
+
+func TestDeprecatedRateLimiter(t *testing.T) {
+	tb := NewTokenBucket(1, 2)
+	assert.True(t, tb.Allow())
+	assert.True(t, tb.Allow())
+	assert.False(t, tb.Allow())
+
+	clock := newTestClock()
+	rl := NewRateLimiter(1, 1)
+	rl.limiter.now = clock.Now
+	assert.True(t, rl.Allow("a"))
+	assert.False(t, rl.Allow("a"))
+	assert.True(t, rl.Allow("b"))
+
+	clock.Add(time.Minute)
+	rl.Cleanup(30 * time.Second)
+	assert.Empty(t, rl.limiter.states)
+}
+
+func TestRedisLimiter(t *testing.T) {
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			mr, client := newTestRedis(t)
+			clock := newTestClock()
+			mr.SetTime(clock.Now())
+			l := NewRedisLimiter(client, algorithm, "")
+			testLimiter(t, l, func(d time.Duration) {
+				clock.Add(d)
+				mr.SetTime(clock.Now())
+			})
+		})
+	}
+}
+
+func TestRedisLimiterScriptFallback(t *testing.T) {
+	_, client := newTestRedis(t)
+	ctx := context.Background()
+	l := NewRedisLimiter(client, AlgorithmGCRA, "")
+	sha := limiterSHA[AlgorithmGCRA]
+
+	// 脚本未缓存时回退到Eval，Eval会缓存脚本
+	exists, err := client.GetClient().ScriptExists(ctx, sha).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, exists)
+
+	res, err := l.Allow(ctx, "k", Limit{Rate: 1})
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	exists, err = client.GetClient().ScriptExists(ctx, sha).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, exists)
+
+	// 脚本缓存被清空（如Redis重启）后仍然可用
+	require.NoError(t, client.GetClient().ScriptFlush(ctx).Err())
+	res, err = l.Allow(ctx, "k", Limit{Rate: 1})
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+}
+
+// testRefund 检查退还额度后可以再次请求
+func testRefund(t *testing.T, l Limiter) {
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Period: time.Second}
+	for range 2 {
+		res, err := l.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+	require.NoError(t, l.(LimitRefunder).Refund(ctx, "k", limit))
+	res, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	res, err = l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	// 不存在的键
+	assert.NoError(t, l.(LimitRefunder).Refund(ctx, "missing", limit))
+}
+
+func TestLimiterRefund(t *testing.T) {
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			testRefund(t, newTestMemoryLimiter(algorithm, newTestClock()))
+
+			mr, client := newTestRedis(t)
+			mr.SetTime(newTestClock().Now())
+			testRefund(t, NewRedisLimiter(client, algorithm, ""))
+		})
+	}
+}
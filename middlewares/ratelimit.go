@@ -3,140 +3,44 @@ package middlewares
 import (
 	"fmt"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
 	"chi"
+	"chi/pkg/logger"
 )
 
 // RateLimitConfig 限流中间件配置
 type RateLimitConfig struct {
-	// Rate 每秒允许的请求数
+	// Rate 每个周期允许的请求数
 	Rate int
+	// Period 限流周期，默认1秒
+	Period time.Duration
 	// Burst 令牌桶容量，允许的突发请求数
 	Burst int
+	// Limiter 限流器，默认每个中间件创建独立的令牌桶内存限流器；
+	// 多副本部署时使用NewRedisLimiter共享计数
+	Limiter Limiter
 	// KeyFunc 生成限流键的函数，默认使用客户端IP
 	KeyFunc func(*chi.Context) string
 	// SkipFunc 跳过限流的条件函数
 	SkipFunc func(*chi.Context) bool
-	// ErrorHandler 限流触发时的错误处理函数
+	// ErrorHandler 限流触发时的错误处理函数，可以通过RateLimitResultKey获取限流结果
 	ErrorHandler func(*chi.Context)
+	// Quotas 与Rate、Burst同时生效的长周期配额，如每个API Key每天10000次
+	Quotas []Limit
+	// Policies 限流策略表，设置后按路由模板和用户等级选择限流规则，忽略Rate、Burst和Quotas
+	Policies *RateLimitPolicies
+	// TierFunc 获取用户等级，用于匹配策略表中的Tier
+	TierFunc func(*chi.Context) string
+	// DisableHeaders 不写入RateLimit-Limit、RateLimit-Remaining、RateLimit-Reset和RateLimit-Policy响应头，
+	// 拒绝请求时仍然写入Retry-After
+	DisableHeaders bool
 }
 
-// TokenBucket 令牌桶结构
-type TokenBucket struct {
-	capacity int64     // 桶容量
-	tokens   int64     // 当前令牌数
-	rate     int64     // 每秒补充的令牌数
-	lastTime time.Time // 上次更新时间
-	mu       sync.Mutex
-}
-
-// NewTokenBucket 创建新的令牌桶
-func NewTokenBucket(rate, capacity int) *TokenBucket {
-	return &TokenBucket{
-		capacity: int64(capacity),
-		tokens:   int64(capacity),
-		rate:     int64(rate),
-		lastTime: time.Now(),
-	}
-}
-
-// Allow 检查是否允许请求通过
-func (tb *TokenBucket) Allow() bool {
-	tb.mu.Lock()
-	defer tb.mu.Unlock()
-
-	now := time.Now()
-	// 计算需要补充的令牌数
-	elapsed := now.Sub(tb.lastTime)
-	tokensToAdd := int64(elapsed.Seconds()) * tb.rate
-
-	// 更新令牌数，不超过桶容量
-	tb.tokens += tokensToAdd
-	if tb.tokens > tb.capacity {
-		tb.tokens = tb.capacity
-	}
-	tb.lastTime = now
-
-	// 检查是否有可用令牌
-	if tb.tokens > 0 {
-		tb.tokens--
-		return true
-	}
-	return false
-}
-
-// RateLimiter 限流器管理器
-type RateLimiter struct {
-	buckets map[string]*TokenBucket
-	mu      sync.RWMutex
-	rate    int
-	burst   int
-}
-
-// NewRateLimiter 创建新的限流器
-func NewRateLimiter(rate, burst int) *RateLimiter {
-	return &RateLimiter{
-		buckets: make(map[string]*TokenBucket),
-		rate:    rate,
-		burst:   burst,
-	}
-}
-
-// Allow 检查指定键是否允许请求
-func (rl *RateLimiter) Allow(key string) bool {
-	rl.mu.RLock()
-	bucket, exists := rl.buckets[key]
-	rl.mu.RUnlock()
-
-	if !exists {
-		rl.mu.Lock()
-		// 双重检查
-		if bucket, exists = rl.buckets[key]; !exists {
-			bucket = NewTokenBucket(rl.rate, rl.burst)
-			rl.buckets[key] = bucket
-		}
-		rl.mu.Unlock()
-	}
-
-	return bucket.Allow()
-}
-
-// Cleanup 清理过期的令牌桶
-func (rl *RateLimiter) Cleanup(maxAge time.Duration) {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	for key, bucket := range rl.buckets {
-		bucket.mu.Lock()
-		if now.Sub(bucket.lastTime) > maxAge {
-			delete(rl.buckets, key)
-		}
-		bucket.mu.Unlock()
-	}
-}
-
-// 全局限流器实例
-var globalRateLimiter *RateLimiter
-var rateLimiterOnce sync.Once
-
-// getGlobalRateLimiter 获取全局限流器实例
-func getGlobalRateLimiter(rate, burst int) *RateLimiter {
-	rateLimiterOnce.Do(func() {
-		globalRateLimiter = NewRateLimiter(rate, burst)
-		// 启动清理协程
-		go func() {
-			ticker := time.NewTicker(5 * time.Minute)
-			defer ticker.Stop()
-			for range ticker.C {
-				globalRateLimiter.Cleanup(10 * time.Minute)
-			}
-		}()
-	})
-	return globalRateLimiter
-}
+// RateLimitResultKey 本次请求剩余额度最少的规则的限流结果，请求被拒绝时为拒绝请求的规则
+var RateLimitResultKey = chi.NewKey[*RateLimitResult]("ratelimit_result")
 
 // defaultKeyFunc 默认的键生成函数，使用客户端IP
 func defaultKeyFunc(c *chi.Context) string {
@@ -165,6 +69,8 @@ func RateLimit(rate, burst int) chi.MiddlewareFunc {
 }
 
 // RateLimitWithConfig 使用自定义配置创建限流中间件
+// 每次调用都使用独立的限流器和规则；多个规则同时生效时依次检查，任一规则拒绝即返回，
+// 限流器实现了LimitRefunder时退还之前的规则已消耗的额度；响应头按剩余额度最少的规则写入
 func RateLimitWithConfig(config RateLimitConfig) chi.MiddlewareFunc {
 	// 设置默认值
 	if config.Rate <= 0 {
@@ -179,8 +85,30 @@ func RateLimitWithConfig(config RateLimitConfig) chi.MiddlewareFunc {
 	if config.ErrorHandler == nil {
 		config.ErrorHandler = defaultErrorHandler
 	}
-
-	rateLimiter := getGlobalRateLimiter(config.Rate, config.Burst)
+	if config.Limiter == nil {
+		config.Limiter = NewMemoryLimiter(AlgorithmTokenBucket)
+	}
+	for _, q := range config.Quotas {
+		checkAlgorithm(q.Algorithm)
+	}
+	if config.Policies != nil {
+		if err := config.Policies.Validate(); err != nil {
+			panic(fmt.Sprintf("middlewares: invalid rate limit policies: %v", err))
+		}
+	}
+	limits := append([]Limit{{Rate: config.Rate, Period: config.Period, Burst: config.Burst}}, config.Quotas...)
+	for _, l := range limits {
+		if err := l.checkInterval(); err != nil {
+			panic(fmt.Sprintf("middlewares: invalid rate limit: %v", err))
+		}
+	}
+	policyHeader := rateLimitPolicyHeader(limits)
+	policyHeaders := make(map[string]string)
+	if config.Policies != nil {
+		for name, l := range config.Policies.Policies {
+			policyHeaders[name] = rateLimitPolicyHeader(l)
+		}
+	}
 
 	return func(c *chi.Context) {
 		// 检查是否跳过限流
@@ -197,9 +125,57 @@ func RateLimitWithConfig(config RateLimitConfig) chi.MiddlewareFunc {
 			return
 		}
 
-		// 检查是否允许请求
-		if !rateLimiter.Allow(key) {
+		// 选择限流规则
+		policy, current, header := "", limits, policyHeader
+		if config.Policies != nil {
+			tier := ""
+			if config.TierFunc != nil {
+				tier = config.TierFunc(c)
+			}
+			if policy, current = config.Policies.match(c, tier); current == nil {
+				c.Next()
+				return
+			}
+			key += ":" + policy
+			header = policyHeaders[policy]
+		}
+
+		// 检查是否允许请求，限流器不可用时跳过该规则，避免Redis故障导致服务不可用
+		ctx := c.Request().Context()
+		var result *RateLimitResult
+		consumed := make([]int, 0, len(current))
+		for i, limit := range current {
+			res, err := config.Limiter.Allow(ctx, key+":"+limitName(limit, i), limit)
+			if err != nil {
+				c.Logger().Warn("rate limiter unavailable", logger.Err(err))
+				continue
+			}
+			if result == nil || !res.Allowed || res.Remaining < result.Remaining {
+				result = res
+			}
+			if !res.Allowed {
+				refundLimits(c, config.Limiter, key, current, consumed)
+				break
+			}
+			consumed = append(consumed, i)
+		}
+		if result == nil {
+			c.Next()
+			return
+		}
+		chi.SetValue(c, RateLimitResultKey, result)
+
+		h := c.Writer().Header()
+		if !config.DisableHeaders {
+			h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.ResetAfter), 10))
+			h.Set("RateLimit-Policy", header)
+		}
+		if !result.Allowed {
+			h.Set("Retry-After", strconv.FormatInt(ceilSeconds(result.RetryAfter), 10))
 			config.ErrorHandler(c)
+			c.Abort()
 			return
 		}
 
@@ -208,6 +184,43 @@ func RateLimitWithConfig(config RateLimitConfig) chi.MiddlewareFunc {
 	}
 }
 
+// limitName 获取规则在限流键中的名称，未命名的规则使用其序号
+func limitName(limit Limit, i int) string {
+	if limit.Name != "" {
+		return limit.Name
+	}
+	return strconv.Itoa(i)
+}
+
+// refundLimits 请求被拒绝时退还之前的规则已消耗的额度
+// consumed: 已消耗额度的规则序号，不包含限流器不可用时跳过的规则
+func refundLimits(c *chi.Context, limiter Limiter, key string, limits []Limit, consumed []int) {
+	refunder, ok := limiter.(LimitRefunder)
+	if !ok {
+		return
+	}
+	for _, i := range consumed {
+		if err := refunder.Refund(c.Request().Context(), key+":"+limitName(limits[i], i), limits[i]); err != nil {
+			c.Logger().Warn("failed to refund rate limit", logger.Err(err))
+		}
+	}
+}
+
+// rateLimitPolicyHeader 生成RateLimit-Policy响应头，如"10;w=1, 10000;w=86400"
+func rateLimitPolicyHeader(limits []Limit) string {
+	parts := make([]string, len(limits))
+	for i, l := range limits {
+		l = l.normalize()
+		parts[i] = fmt.Sprintf("%d;w=%d", l.Rate, ceilSeconds(l.Period))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ceilSeconds 将时间向上取整为秒
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
 // RateLimitByIP 基于IP的限流中间件
 func RateLimitByIP(rate, burst int) chi.MiddlewareFunc {
 	return RateLimitWithConfig(RateLimitConfig{
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"chi"
+
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// 限流策略表
+// =============================================================================
+
+// RateLimitRule 限流策略匹配规则
+type RateLimitRule struct {
+	// Route 路由模板，可以带请求方法，如"/api/orders/:id"或"POST /auth/login"，为空时匹配所有路由
+	Route string `json:"route" yaml:"route"`
+	// Tier 用户等级，由RateLimitConfig.TierFunc获取，为空时匹配所有等级
+	Tier string `json:"tier" yaml:"tier"`
+	// Policy 使用的策略名称
+	Policy string `json:"policy" yaml:"policy"`
+}
+
+// RateLimitPolicies 限流策略表
+// 按顺序匹配Rules，使用第一条匹配规则的策略，没有匹配的规则时不限流；
+// 使用同一策略的路由共享计数
+type RateLimitPolicies struct {
+	// Policies 策略名称到限流规则的映射，同一策略的多个规则同时生效，如突发限制叠加每日配额
+	Policies map[string][]Limit `json:"policies" yaml:"policies"`
+	// Rules 策略匹配规则
+	Rules []RateLimitRule `json:"rules" yaml:"rules"`
+}
+
+// LoadRateLimitPolicies 从文件加载限流策略表
+// 支持YAML和JSON格式，周期使用"1s"、"24h"形式
+// path: 策略文件路径
+// 返回值: 限流策略表和错误信息
+func LoadRateLimitPolicies(path string) (*RateLimitPolicies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit policies: %w", err)
+	}
+	// JSON是YAML的子集，统一使用yaml标签解码以支持时间格式
+	policies := &RateLimitPolicies{}
+	if err := yaml.Unmarshal(data, policies); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit policies: %w", err)
+	}
+	if err := policies.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit policies: %w", err)
+	}
+	return policies, nil
+}
+
+// Validate 检查策略表
+// 返回值: 规则引用了不存在的策略、限流规则无效或同一策略中的规则名称重复时返回错误，
+// 未命名的规则使用其序号作为名称
+func (p *RateLimitPolicies) Validate() error {
+	var errs []error
+	for policy, limits := range p.Policies {
+		if len(limits) == 0 {
+			errs = append(errs, fmt.Errorf("policy %q has no limits", policy))
+		}
+		names := make(map[string]struct{}, len(limits))
+		for i, l := range limits {
+			name := limitName(l, i)
+			if l.Rate <= 0 || l.Period < 0 || l.Burst < 0 {
+				errs = append(errs, fmt.Errorf("policy %q: invalid limit %d", policy, i))
+			} else if err := l.checkInterval(); err != nil {
+				errs = append(errs, fmt.Errorf("policy %q: limit %d: %w", policy, i, err))
+			}
+			if !l.Algorithm.valid() {
+				errs = append(errs, fmt.Errorf("policy %q: unknown algorithm %q", policy, l.Algorithm))
+			}
+			if _, ok := names[name]; ok {
+				errs = append(errs, fmt.Errorf("policy %q: duplicate limit name %q", policy, name))
+			}
+			names[name] = struct{}{}
+		}
+	}
+	for i, r := range p.Rules {
+		if _, ok := p.Policies[r.Policy]; !ok {
+			errs = append(errs, fmt.Errorf("rule %d: unknown policy %q", i, r.Policy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// match 查找请求匹配的策略
+// 返回值: 策略名称和限流规则，没有匹配时为空
+func (p *RateLimitPolicies) match(c *chi.Context, tier string) (string, []Limit) {
+	route := c.FullPath()
+	method := c.Request().Method
+	for _, r := range p.Rules {
+		if r.Tier != "" && r.Tier != tier {
+			continue
+		}
+		if r.Route != "" {
+			m, path, ok := strings.Cut(r.Route, " ")
+			if !ok {
+				m, path = "", r.Route
+			}
+			if path != route || (m != "" && !strings.EqualFold(m, method)) {
+				continue
+			}
+		}
+		return r.Policy, p.Policies[r.Policy]
+	}
+	return "", nil
+}
@@ -0,0 +1,225 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRateLimitServer 创建注册了测试路由的限流服务器
+func newRateLimitServer(config RateLimitConfig) *chi.Server {
+	s := newTestServer(RateLimitWithConfig(config))
+	ok := func(c *chi.Context) { c.Status(http.StatusNoContent) }
+	s.GET("/ping", ok)
+	s.GET("/login", ok)
+	s.POST("/login", ok)
+	s.GET("/api/items/:id", ok)
+	return s
+}
+
+// get 创建请求
+func get(method, path string, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	clock := newTestClock()
+	config := RateLimitConfig{
+		Rate:    2,
+		Burst:   2,
+		Limiter: newTestMemoryLimiter(AlgorithmTokenBucket, clock),
+		Quotas:  []Limit{{Name: "daily", Rate: 100, Period: 24 * time.Hour, Algorithm: AlgorithmSlidingWindow}},
+	}
+	s := newRateLimitServer(config)
+
+	w := serve(s, get(http.MethodGet, "/ping"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=1, 100;w=86400", w.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	serve(s, get(http.MethodGet, "/ping"))
+	w = serve(s, get(http.MethodGet, "/ping"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	// 关闭RateLimit-*响应头时仍然写入Retry-After
+	config.DisableHeaders = true
+	config.Limiter = newTestMemoryLimiter(AlgorithmTokenBucket, clock)
+	s = newRateLimitServer(config)
+	for range 2 {
+		w = serve(s, get(http.MethodGet, "/ping"))
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+	w = serve(s, get(http.MethodGet, "/ping"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitSubSecondRefill(t *testing.T) {
+	clock := newTestClock()
+	s := newRateLimitServer(RateLimitConfig{Rate: 10, Burst: 1, Limiter: newTestMemoryLimiter(AlgorithmTokenBucket, clock)})
+
+	assert.Equal(t, http.StatusNoContent, serve(s, get(http.MethodGet, "/ping")).Code)
+	clock.Add(50 * time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, serve(s, get(http.MethodGet, "/ping")).Code)
+	for range 5 {
+		clock.Add(100 * time.Millisecond)
+		assert.Equal(t, http.StatusNoContent, serve(s, get(http.MethodGet, "/ping")).Code)
+	}
+}
+
+func TestRateLimitStackedRefund(t *testing.T) {
+	policies := &RateLimitPolicies{
+		Policies: map[string][]Limit{
+			"api": {
+				{Name: "daily", Rate: 3, Period: 24 * time.Hour, Algorithm: AlgorithmSlidingWindow},
+				{Name: "burst", Rate: 1, Period: time.Second},
+			},
+		},
+		Rules: []RateLimitRule{{Policy: "api"}},
+	}
+	for _, name := range []string{"memory", "redis"} {
+		t.Run(name, func(t *testing.T) {
+			clock := newTestClock()
+			advance := clock.Add
+			var limiter Limiter = newTestMemoryLimiter(AlgorithmTokenBucket, clock)
+			if name == "redis" {
+				mr, client := newTestRedis(t)
+				mr.SetTime(clock.Now())
+				limiter = NewRedisLimiter(client, AlgorithmTokenBucket, "")
+				advance = func(d time.Duration) {
+					clock.Add(d)
+					mr.SetTime(clock.Now())
+				}
+			}
+			s := newRateLimitServer(RateLimitConfig{Limiter: limiter, Policies: policies})
+
+			assert.Equal(t, http.StatusNoContent, serve(s, get(http.MethodGet, "/ping")).Code)
+			// 被burst拒绝的请求不占用每日配额
+			for range 3 {
+				assert.Equal(t, http.StatusTooManyRequests, serve(s, get(http.MethodGet, "/ping")).Code)
+			}
+			for range 2 {
+				advance(time.Second)
+				assert.Equal(t, http.StatusNoContent, serve(s, get(http.MethodGet, "/ping")).Code)
+			}
+			advance(time.Second)
+			w := serve(s, get(http.MethodGet, "/ping"))
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+		})
+	}
+}
+
+func TestRateLimitPolicies(t *testing.T) {
+	policies := &RateLimitPolicies{
+		Policies: map[string][]Limit{
+			"login": {{Rate: 1, Period: time.Minute}},
+			"pro":   {{Rate: 100, Period: time.Minute}, {Rate: 1000, Period: time.Hour}},
+			"free":  {{Rate: 1, Period: time.Minute}},
+		},
+		Rules: []RateLimitRule{
+			{Route: "POST /login", Policy: "login"},
+			{Tier: "pro", Policy: "pro"},
+			{Route: "/api/items/:id", Policy: "free"},
+		},
+	}
+	s := newRateLimitServer(RateLimitConfig{
+		Limiter:  newTestMemoryLimiter(AlgorithmTokenBucket, newTestClock()),
+		Policies: policies,
+		TierFunc: func(c *chi.Context) string { return c.GetHeader("X-Tier") },
+	})
+
+	// 按请求方法和路由模板匹配
+	assert.Equal(t, http.StatusNoContent, serve(s, get(http.MethodPost, "/login")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(s, get(http.MethodPost, "/login")).Code)
+	w := serve(s, get(http.MethodGet, "/login"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Policy"), "no matching rule")
+
+	// 同一策略的路由共享计数
+	w = serve(s, get(http.MethodGet, "/api/items/1"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "1;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, http.StatusTooManyRequests, serve(s, get(http.MethodGet, "/api/items/2")).Code)
+
+	// 用户等级规则排在路由规则之前
+	w = serve(s, get(http.MethodGet, "/api/items/3", "X-Tier", "pro"))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "100;w=60, 1000;w=3600", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "99", w.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitPoliciesValidate(t *testing.T) {
+	valid := &RateLimitPolicies{
+		Policies: map[string][]Limit{"api": {{Rate: 10}, {Rate: 1000, Period: time.Hour}}},
+		Rules:    []RateLimitRule{{Policy: "api"}},
+	}
+	assert.NoError(t, valid.Validate())
+
+	tests := map[string]*RateLimitPolicies{
+		"DuplicateName":    {Policies: map[string][]Limit{"api": {{Name: "a", Rate: 1}, {Name: "a", Rate: 2}}}},
+		"IndexCollision":   {Policies: map[string][]Limit{"api": {{Name: "1", Rate: 1}, {Rate: 2}}}},
+		"Empty":            {Policies: map[string][]Limit{"api": {}}},
+		"InvalidRate":      {Policies: map[string][]Limit{"api": {{Rate: 0}}}},
+		"UnknownAlgorithm": {Policies: map[string][]Limit{"api": {{Rate: 1, Algorithm: "leaky"}}}},
+		"UnknownPolicy":    {Rules: []RateLimitRule{{Policy: "missing"}}},
+		"SubNanosecond":    {Policies: map[string][]Limit{"api": {{Rate: 2e9, Period: time.Second}}}},
+	}
+	for name, p := range tests {
+		assert.Error(t, p.Validate(), name)
+	}
+
+	assert.Panics(t, func() { RateLimitWithConfig(RateLimitConfig{Rate: 10, Period: 5}) })
+	assert.Panics(t, func() {
+		RateLimitWithConfig(RateLimitConfig{Rate: 1, Quotas: []Limit{{Rate: 10, Period: 5}}})
+	})
+}
+
+func TestLoadRateLimitPolicies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+policies:
+  free:
+    - name: burst
+      rate: 10
+      period: 1s
+    - name: daily
+      rate: 1000
+      period: 24h
+      algorithm: sliding_window
+rules:
+  - route: GET /api/items/:id
+    tier: free
+    policy: free
+`), 0644))
+
+	p, err := LoadRateLimitPolicies(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Limit{
+		{Name: "burst", Rate: 10, Period: time.Second},
+		{Name: "daily", Rate: 1000, Period: 24 * time.Hour, Algorithm: AlgorithmSlidingWindow},
+	}, p.Policies["free"])
+	assert.Equal(t, []RateLimitRule{{Route: "GET /api/items/:id", Tier: "free", Policy: "free"}}, p.Rules)
+
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - policy: missing\n"), 0644))
+	_, err = LoadRateLimitPolicies(path)
+	assert.Error(t, err)
+}
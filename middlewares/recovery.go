@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"chi"
+	"chi/pkg/logger"
 )
 
 // RecoveryConfig panic恢复中间件配置
@@ -34,15 +35,17 @@ var DefaultRecoveryConfig = RecoveryConfig{
 }
 
 // defaultLogFunc 默认日志记录函数
+// 通过请求范围的日志记录器输出，日志附带请求ID和链路追踪字段
 func defaultLogFunc(c *chi.Context, err interface{}, stack []byte) {
-	timeFormat := "2006/01/02 - 15:04:05"
-	fmt.Printf("[PANIC RECOVERY] %s | %s %s | Error: %v\n%s\n",
-		time.Now().Format(timeFormat),
-		c.Request().Method,
-		c.Request().URL.Path,
-		err,
-		stack,
-	)
+	fields := []logger.Field{
+		logger.String("method", c.Request().Method),
+		logger.String("path", c.Request().URL.Path),
+		logger.String("error", formatPanicError(err)),
+	}
+	if len(stack) > 0 {
+		fields = append(fields, logger.String("stack", string(stack)))
+	}
+	c.Logger().Error("panic recovered", fields...)
 }
 
 // defaultRecoveryHandler 默认恢复处理函数
@@ -211,26 +214,27 @@ func RecoveryForDevelopment() chi.MiddlewareFunc {
 	})
 }
 
-// RecoveryWithMetrics 带指标统计的恢复中间件
-// 可以集成Prometheus等监控系统
-func RecoveryWithMetrics() chi.MiddlewareFunc {
-	// 这里可以集成指标收集逻辑
-	var panicCount int64
+// PanicRecorder panic指标记录器，pkg/metrics.Metrics实现了该接口
+type PanicRecorder interface {
+	RecordPanic(c *chi.Context, err interface{})
+}
 
+// RecoveryWithMetrics 带指标统计的恢复中间件
+// 每次panic都会调用记录器，如pkg/metrics.Metrics会按路由模板统计panic次数
+// recorders: panic指标记录器
+func RecoveryWithMetrics(recorders ...PanicRecorder) chi.MiddlewareFunc {
 	return RecoveryWithConfig(RecoveryConfig{
 		StackSize:         4 << 10,
 		DisableStackAll:   false,
 		DisablePrintStack: false,
 		LogFunc: func(c *chi.Context, err interface{}, stack []byte) {
-			// 增加panic计数
-			panicCount++
+			// 记录panic指标
+			for _, r := range recorders {
+				r.RecordPanic(c, err)
+			}
 
 			// 记录日志
 			defaultLogFunc(c, err, stack)
-
-			// 这里可以发送指标到监控系统
-			// metrics.IncrementPanicCounter()
-			// metrics.RecordPanicPath(c.Request().URL.Path)
 		},
 		RecoveryHandler: defaultRecoveryHandler,
 	})
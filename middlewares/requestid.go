@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"chi"
+	"chi/pkg/logger"
+)
+
+// RequestIDConfig 请求ID中间件配置
+type RequestIDConfig struct {
+	// Header 读取和写入请求ID的请求头
+	Header string
+	// Generator 生成请求ID的函数
+	Generator func() string
+	// IgnoreIncoming 是否忽略客户端传入的请求ID，总是生成新的
+	IgnoreIncoming bool
+}
+
+// DefaultRequestIDConfig 默认请求ID配置
+var DefaultRequestIDConfig = RequestIDConfig{
+	Header:    "X-Request-ID",
+	Generator: generateRequestID,
+}
+
+// maxRequestIDLength 客户端传入的请求ID的最大长度
+const maxRequestIDLength = 128
+
+// RequestID 创建请求ID中间件
+// 使用默认配置的请求ID中间件
+func RequestID() chi.MiddlewareFunc {
+	return RequestIDWithConfig(DefaultRequestIDConfig)
+}
+
+// RequestIDWithConfig 使用自定义配置创建请求ID中间件
+// 沿用客户端传入的合法请求ID，否则生成新的请求ID，写入响应头、chi.RequestIDKey和请求上下文，
+// 之后的Context.Std、Context.Logger和Res响应都会带上该请求ID
+// config: 请求ID配置参数
+// 返回值: 配置好的请求ID中间件函数
+func RequestIDWithConfig(config RequestIDConfig) chi.MiddlewareFunc {
+	if config.Header == "" {
+		config.Header = DefaultRequestIDConfig.Header
+	}
+	if config.Generator == nil {
+		config.Generator = DefaultRequestIDConfig.Generator
+	}
+
+	return func(c *chi.Context) {
+		var id string
+		if !config.IgnoreIncoming {
+			id = c.GetHeader(config.Header)
+			if !validRequestID(id) {
+				id = ""
+			}
+		}
+		if id == "" {
+			id = config.Generator()
+		}
+
+		chi.SetValue(c, chi.RequestIDKey, id)
+		c.Header(config.Header, id)
+		req := c.Request()
+		c.Context.Request = req.WithContext(logger.WithRequestID(req.Context(), id))
+
+		c.Next()
+	}
+}
+
+// generateRequestID 生成32位十六进制随机请求ID
+func generateRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID 检查客户端传入的请求ID，只接受长度有限的可见ASCII字符，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
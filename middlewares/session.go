@@ -0,0 +1,345 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"chi"
+	"chi/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionData 会话数据
+type SessionData struct {
+	// ID 会话ID
+	ID string
+	// Values 会话中的值
+	Values map[string]any
+	// ExpiresAt 过期时间
+	ExpiresAt time.Time
+}
+
+// SessionStore 会话存储
+type SessionStore interface {
+	// Load 根据Cookie值加载会话，会话不存在、已过期或Cookie无效时返回nil
+	Load(ctx context.Context, cookie string) (*SessionData, error)
+	// Save 保存会话，返回写入Cookie的值
+	Save(ctx context.Context, data *SessionData) (string, error)
+	// Delete 删除会话
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig 会话中间件配置
+type SessionConfig struct {
+	// Store 会话存储，如NewCookieStore、NewRedisStore
+	Store SessionStore
+	// CookieName 保存会话的Cookie名称
+	CookieName string
+	// MaxAge 会话有效期
+	MaxAge time.Duration
+	// Sliding 是否启用滑动过期，每次请求都延长有效期
+	Sliding bool
+	// Path Cookie路径
+	Path string
+	// Domain Cookie域名
+	Domain string
+	// Secure 是否只通过HTTPS发送Cookie
+	Secure bool
+	// HttpOnly 是否禁止JavaScript读取Cookie
+	HttpOnly bool
+	// SameSite Cookie的SameSite属性
+	SameSite http.SameSite
+	// ErrorHandler 加载会话失败时的处理函数，默认返回chi.ErrUnavailable
+	ErrorHandler func(c *chi.Context, err error)
+}
+
+// DefaultSessionConfig 默认会话配置
+var DefaultSessionConfig = SessionConfig{
+	CookieName:   "chi_session",
+	MaxAge:       24 * time.Hour,
+	Sliding:      true,
+	Path:         "/",
+	HttpOnly:     true,
+	SameSite:     http.SameSiteLaxMode,
+	ErrorHandler: defaultSessionErrorHandler,
+}
+
+// flashKey 一次性消息在会话中的键
+const flashKey = "_flash"
+
+// defaultSessionErrorHandler 默认会话加载失败处理函数
+func defaultSessionErrorHandler(c *chi.Context, err error) {
+	chi.FailRes(c, chi.ErrUnavailable.Wrap(err))
+}
+
+// Session 创建会话中间件
+// 请求开始时加载会话，处理函数通过c.Session()读写，修改在第一次写入响应体或处理链结束时自动保存，
+// 因此c.Status之后的修改仍然有效，写入响应体之后的修改不再保存；
+// 启用滑动过期时每次请求都会重新保存以延长有效期
+// config: 会话配置参数，Store必填；通常复制DefaultSessionConfig后修改
+// 返回值: 配置好的会话中间件函数
+func Session(config SessionConfig) chi.MiddlewareFunc {
+	if config.Store == nil {
+		panic("middlewares: Session requires a Store")
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultSessionConfig.CookieName
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultSessionConfig.MaxAge
+	}
+	if config.Path == "" {
+		config.Path = DefaultSessionConfig.Path
+	}
+	if config.SameSite == 0 {
+		config.SameSite = DefaultSessionConfig.SameSite
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultSessionConfig.ErrorHandler
+	}
+
+	return func(c *chi.Context) {
+		ctx := c.Request().Context()
+		var data *SessionData
+		if cookie, err := c.Cookie(config.CookieName); err == nil && cookie != "" {
+			if data, err = config.Store.Load(ctx, cookie); err != nil {
+				config.ErrorHandler(c, err)
+				c.Abort()
+				return
+			}
+		}
+
+		s := &session{config: &config, c: c}
+		if data == nil {
+			s.data = &SessionData{ID: newSessionID(), Values: make(map[string]any)}
+			s.isNew = true
+		} else {
+			s.data = data
+		}
+		chi.SetValue(c, chi.SessionKey, chi.Session(s))
+
+		writer := &sessionWriter{ResponseWriter: c.Context.Writer, session: s}
+		c.Context.Writer = writer
+		c.Next()
+		s.commit()
+	}
+}
+
+// =============================================================================
+// 会话实现
+// =============================================================================
+
+// session 实现chi.Session接口
+type session struct {
+	config *SessionConfig
+	c      *chi.Context
+
+	mu        sync.Mutex
+	data      *SessionData
+	isNew     bool
+	modified  bool
+	destroyed bool
+	// oldID 重新生成前的会话ID，提交时从存储中删除
+	oldID     string
+	committed bool
+}
+
+// ID 实现chi.Session接口
+func (s *session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.ID
+}
+
+// IsNew 实现chi.Session接口
+func (s *session) IsNew() bool {
+	return s.isNew
+}
+
+// Get 实现chi.Session接口
+func (s *session) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data.Values[key]
+	return v, ok
+}
+
+// Set 实现chi.Session接口
+func (s *session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Values[key] = value
+	s.modified = true
+}
+
+// Delete 实现chi.Session接口
+func (s *session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Values[key]; ok {
+		delete(s.data.Values, key)
+		s.modified = true
+	}
+}
+
+// Clear 实现chi.Session接口
+func (s *session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Values = make(map[string]any)
+	s.modified = true
+}
+
+// AddFlash 实现chi.Session接口
+func (s *session) AddFlash(value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, _ := s.data.Values[flashKey].([]any)
+	s.data.Values[flashKey] = append(flashes, value)
+	s.modified = true
+}
+
+// Flashes 实现chi.Session接口
+func (s *session) Flashes() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes, ok := s.data.Values[flashKey].([]any)
+	if !ok {
+		return nil
+	}
+	delete(s.data.Values, flashKey)
+	s.modified = true
+	return flashes
+}
+
+// Regenerate 实现chi.Session接口
+func (s *session) Regenerate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isNew && s.oldID == "" {
+		s.oldID = s.data.ID
+	}
+	s.data.ID = newSessionID()
+	s.modified = true
+}
+
+// Destroy 实现chi.Session接口
+func (s *session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+}
+
+// commit 保存会话并写入Cookie，只执行一次
+// 在响应头发送前调用，之后的修改不再保存
+func (s *session) commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.committed {
+		return
+	}
+	s.committed = true
+
+	ctx := s.c.Request().Context()
+	cfg := s.config
+	if s.destroyed {
+		if !s.isNew {
+			s.report(cfg.Store.Delete(ctx, s.data.ID))
+		}
+		if s.oldID != "" {
+			s.report(cfg.Store.Delete(ctx, s.oldID))
+		}
+		s.setCookie("", -1)
+		return
+	}
+
+	// 新会话未写入数据时不创建Cookie
+	if s.isNew && !s.modified {
+		return
+	}
+	if !s.modified && !cfg.Sliding {
+		return
+	}
+
+	if s.oldID != "" {
+		s.report(cfg.Store.Delete(ctx, s.oldID))
+	}
+	s.data.ExpiresAt = time.Now().Add(cfg.MaxAge)
+	value, err := cfg.Store.Save(ctx, s.data)
+	if err != nil {
+		s.report(err)
+		return
+	}
+	s.setCookie(value, int(cfg.MaxAge/time.Second))
+}
+
+// setCookie 写入会话Cookie
+func (s *session) setCookie(value string, maxAge int) {
+	cfg := s.config
+	http.SetCookie(s.c.Context.Writer, &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    value,
+		Path:     cfg.Path,
+		Domain:   cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   cfg.Secure,
+		HttpOnly: cfg.HttpOnly,
+		SameSite: cfg.SameSite,
+	})
+}
+
+// report 记录保存会话失败的错误，此时响应已无法修改
+func (s *session) report(err error) {
+	if err == nil {
+		return
+	}
+	s.c.Context.Error(err)
+	s.c.Logger().Error("failed to save session", logger.Err(err))
+}
+
+// newSessionID 生成随机会话ID
+func newSessionID() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// =============================================================================
+// 响应写入
+// =============================================================================
+
+// sessionWriter 在响应头发送前保存会话
+// WriteHeader只记录状态码，响应头在第一次写入响应体或WriteHeaderNow时才发送，此时再保存会话
+type sessionWriter struct {
+	gin.ResponseWriter
+	session *session
+}
+
+// WriteHeaderNow 实现gin.ResponseWriter接口
+func (w *sessionWriter) WriteHeaderNow() {
+	w.session.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write 实现http.ResponseWriter接口
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.session.commit()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 实现gin.ResponseWriter接口
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.session.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush 实现http.Flusher接口
+func (w *sessionWriter) Flush() {
+	w.session.commit()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,178 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"chi/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func init() {
+	// 一次性消息保存为[]any
+	gob.Register([]any{})
+}
+
+// encodeSession 使用gob编码会话数据
+func encodeSession(data *SessionData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSession 解码会话数据
+func decodeSession(b []byte) (*SessionData, error) {
+	data := &SessionData{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(data); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if data.Values == nil {
+		data.Values = make(map[string]any)
+	}
+	return data, nil
+}
+
+// =============================================================================
+// Cookie存储
+// =============================================================================
+
+// CookieStore 将会话数据加密后保存在Cookie中
+// 使用AES-GCM加密和认证，客户端无法读取或篡改；Cookie大小限制约4KB，适合少量数据。
+// 服务端不保存状态，Destroy和Regenerate只能让浏览器删除或替换Cookie，
+// 旧Cookie的副本在过期前仍然有效，需要服务端撤销会话时使用RedisStore
+type CookieStore struct {
+	aeads []cipher.AEAD
+}
+
+// NewCookieStore 创建Cookie会话存储
+// keys: AES密钥，长度为16、24或32字节；第一个密钥用于加密，所有密钥都用于解密，便于轮换密钥
+// 返回值: Cookie会话存储和错误信息
+func NewCookieStore(keys ...[]byte) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("cookie store requires at least one key")
+	}
+	store := &CookieStore{}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie store key: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie store key: %w", err)
+		}
+		store.aeads = append(store.aeads, aead)
+	}
+	return store, nil
+}
+
+// Load 实现SessionStore接口
+func (s *CookieStore) Load(ctx context.Context, cookie string) (*SessionData, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil {
+		return nil, nil
+	}
+	for _, aead := range s.aeads {
+		if len(b) < aead.NonceSize() {
+			continue
+		}
+		plain, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+		if err != nil {
+			continue
+		}
+		data, err := decodeSession(plain)
+		if err != nil || time.Now().After(data.ExpiresAt) {
+			return nil, nil
+		}
+		return data, nil
+	}
+	// 无法解密视为没有会话
+	return nil, nil
+}
+
+// Save 实现SessionStore接口
+func (s *CookieStore) Save(ctx context.Context, data *SessionData) (string, error) {
+	plain, err := encodeSession(data)
+	if err != nil {
+		return "", err
+	}
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// Delete 实现SessionStore接口，数据保存在Cookie中，删除Cookie即可；已复制的Cookie无法撤销
+func (s *CookieStore) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// =============================================================================
+// Redis存储
+// =============================================================================
+
+// RedisStore 将会话数据保存在Redis中，Cookie中只保存会话ID
+type RedisStore struct {
+	client *cache.Client
+	prefix string
+}
+
+// NewRedisStore 创建Redis会话存储
+// client: pkg/cache客户端
+// prefix: 会话键前缀，为空时使用"session:"
+// 返回值: Redis会话存储
+func NewRedisStore(client *cache.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "session:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Load 实现SessionStore接口
+func (s *RedisStore) Load(ctx context.Context, cookie string) (*SessionData, error) {
+	b, err := s.client.GetClient().Get(ctx, s.prefix+cookie).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	data, err := decodeSession(b)
+	if err != nil {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// Save 实现SessionStore接口，过期时间与会话有效期一致
+func (s *RedisStore) Save(ctx context.Context, data *SessionData) (string, error) {
+	b, err := encodeSession(data)
+	if err != nil {
+		return "", err
+	}
+	if err := s.client.GetClient().Set(ctx, s.prefix+data.ID, b, time.Until(data.ExpiresAt)).Err(); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+	return data.ID, nil
+}
+
+// Delete 实现SessionStore接口
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	if err := s.client.GetClient().Del(ctx, s.prefix+id).Err(); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chi"
+	"chi/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedis 创建基于miniredis的cache客户端
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *cache.Client) {
+	mr := miniredis.RunT(t)
+	client := cache.NewClient(&cache.Config{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+// sessionCookie 获取响应中的会话Cookie
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == DefaultSessionConfig.CookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// withCookie 创建携带Cookie的请求
+func withCookie(method, path string, cookie *http.Cookie) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	if cookie != nil {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return req
+}
+
+// newSessionServer 创建注册了常用会话路由的测试服务器
+func newSessionServer(store SessionStore) *chi.Server {
+	cfg := DefaultSessionConfig
+	cfg.Store = store
+	s := newTestServer(Session(cfg))
+	s.GET("/get", func(c *chi.Context) {
+		user, _ := chi.SessionValue[string](c, "user")
+		c.String(http.StatusOK, user)
+	})
+	s.POST("/login", func(c *chi.Context) {
+		c.Session().Regenerate()
+		c.Session().Set("user", c.Query("user"))
+		c.Status(http.StatusNoContent)
+	})
+	s.POST("/logout", func(c *chi.Context) {
+		c.Session().Destroy()
+		c.Status(http.StatusNoContent)
+	})
+	return s
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	oldKey := []byte("0123456789abcdef")
+	store, err := NewCookieStore(oldKey)
+	require.NoError(t, err)
+	s := newSessionServer(store)
+
+	// 没有写入数据的新会话不创建Cookie
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/get", nil))
+	assert.Nil(t, sessionCookie(w))
+
+	w = serve(s, httptest.NewRequest(http.MethodPost, "/login?user=tom", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	cookie := sessionCookie(w)
+	require.NotNil(t, cookie)
+	assert.Equal(t, "tom", serve(s, withCookie(http.MethodGet, "/get", cookie)).Body.String())
+
+	// 篡改的Cookie视为没有会话
+	tampered := *cookie
+	b := []byte(tampered.Value)
+	b[len(b)/2] ^= 1
+	tampered.Value = string(b)
+	assert.Empty(t, serve(s, withCookie(http.MethodGet, "/get", &tampered)).Body.String())
+
+	// 轮换密钥后仍然可以解密旧Cookie
+	rotated, err := NewCookieStore([]byte("fedcba9876543210"), oldKey)
+	require.NoError(t, err)
+	assert.Equal(t, "tom", serve(newSessionServer(rotated), withCookie(http.MethodGet, "/get", cookie)).Body.String())
+
+	w = serve(s, withCookie(http.MethodPost, "/logout", cookie))
+	require.NotNil(t, sessionCookie(w))
+	assert.Negative(t, sessionCookie(w).MaxAge)
+}
+
+func TestCookieStoreExpiry(t *testing.T) {
+	store, err := NewCookieStore([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	value, err := store.Save(ctx, &SessionData{ID: "s1", Values: map[string]any{"user": "tom"}, ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	data, err := store.Load(ctx, value)
+	require.NoError(t, err)
+	require.NotNil(t, data)
+	assert.Equal(t, "tom", data.Values["user"])
+
+	value, err = store.Save(ctx, &SessionData{ID: "s1", Values: map[string]any{}, ExpiresAt: time.Now().Add(-time.Second)})
+	require.NoError(t, err)
+	data, err = store.Load(ctx, value)
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestSessionRedisStore(t *testing.T) {
+	mr, client := newTestRedis(t)
+	s := newSessionServer(NewRedisStore(client, ""))
+
+	w := serve(s, httptest.NewRequest(http.MethodPost, "/login?user=tom", nil))
+	first := sessionCookie(w)
+	require.NotNil(t, first)
+	assert.True(t, mr.Exists("session:"+first.Value))
+	assert.Equal(t, "tom", serve(s, withCookie(http.MethodGet, "/get", first)).Body.String())
+
+	// Regenerate更换会话ID并删除旧会话
+	w = serve(s, withCookie(http.MethodPost, "/login?user=jerry", first))
+	second := sessionCookie(w)
+	require.NotNil(t, second)
+	assert.NotEqual(t, first.Value, second.Value)
+	assert.False(t, mr.Exists("session:"+first.Value))
+	assert.Empty(t, serve(s, withCookie(http.MethodGet, "/get", first)).Body.String())
+	assert.Equal(t, "jerry", serve(s, withCookie(http.MethodGet, "/get", second)).Body.String())
+
+	// Destroy删除会话和Cookie
+	w = serve(s, withCookie(http.MethodPost, "/logout", second))
+	require.NotNil(t, sessionCookie(w))
+	assert.Negative(t, sessionCookie(w).MaxAge)
+	assert.False(t, mr.Exists("session:"+second.Value))
+	assert.Empty(t, serve(s, withCookie(http.MethodGet, "/get", second)).Body.String())
+
+	// 会话过期时间与MaxAge一致
+	w = serve(s, httptest.NewRequest(http.MethodPost, "/login?user=tom", nil))
+	assert.InDelta(t, DefaultSessionConfig.MaxAge, mr.TTL("session:"+sessionCookie(w).Value), float64(time.Second))
+}
+
+func TestSessionCommit(t *testing.T) {
+	store, err := NewCookieStore([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	cfg := DefaultSessionConfig
+	cfg.Store = store
+	s := newTestServer(Session(cfg))
+	s.GET("/status", func(c *chi.Context) {
+		c.Status(http.StatusAccepted)
+		c.Session().Set("user", "tom")
+	})
+	s.GET("/body", func(c *chi.Context) {
+		c.Session().Set("user", "tom")
+		c.String(http.StatusOK, "ok")
+		c.Session().Set("user", "jerry")
+	})
+	s.GET("/flash", func(c *chi.Context) {
+		c.Session().AddFlash("saved")
+		c.Status(http.StatusNoContent)
+	})
+	s.GET("/get", func(c *chi.Context) {
+		user, _ := chi.SessionValue[string](c, "user")
+		c.JSON(http.StatusOK, map[string]any{"user": user, "flashes": c.Session().Flashes()})
+	})
+
+	// c.Status之后的修改仍然保存
+	w := serve(s, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, serve(s, withCookie(http.MethodGet, "/get", sessionCookie(w))).Body.String(), `"user":"tom"`)
+
+	// 写入响应体之后的修改不再保存
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/body", nil))
+	assert.Contains(t, serve(s, withCookie(http.MethodGet, "/get", sessionCookie(w))).Body.String(), `"user":"tom"`)
+
+	// 一次性消息读取后清除
+	w = serve(s, httptest.NewRequest(http.MethodGet, "/flash", nil))
+	w = serve(s, withCookie(http.MethodGet, "/get", sessionCookie(w)))
+	assert.Contains(t, w.Body.String(), `"flashes":["saved"]`)
+	w = serve(s, withCookie(http.MethodGet, "/get", sessionCookie(w)))
+	assert.Contains(t, w.Body.String(), `"flashes":null`)
+}
@@ -0,0 +1,570 @@
+package chi
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// OpenAPIConfig OpenAPI文档服务配置
+// 文档页面本身不内置静态资源，AssetsURL为空时从公共CDN（cdn.jsdelivr.net）加载，
+// 无法访问外网或启用了限制外部脚本的CSP时，需要自托管资源并设置AssetsURL
+type OpenAPIConfig struct {
+	// Title 文档标题，默认使用服务器名称
+	Title string
+	// Version 文档版本，默认使用服务器版本
+	Version string
+	// Description 文档描述，默认使用服务器描述
+	Description string
+	// Path OpenAPI JSON文档路由
+	Path string
+	// UIPath 文档页面路由
+	UIPath string
+	// UI 文档页面类型，支持"swagger"、"redoc"，"none"表示不注册页面
+	UI string
+	// AssetsURL 文档页面静态资源地址，为空时使用jsDelivr CDN
+	// swagger需要该地址下的swagger-ui.css和swagger-ui-bundle.js（swagger-ui-dist包），
+	// redoc需要bundles/redoc.standalone.js（redoc包）
+	AssetsURL string
+}
+
+// DefaultOpenAPIConfig 默认OpenAPI文档服务配置
+var DefaultOpenAPIConfig = OpenAPIConfig{
+	Path:   "/openapi.json",
+	UIPath: "/docs",
+	UI:     "swagger",
+}
+
+// OpenAPI OpenAPI 3.1文档
+type OpenAPI struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo 文档基本信息
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIComponents 可复用组件
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas"`
+}
+
+// OpenAPIOperation 接口操作描述
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter 接口参数描述
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody 请求体描述
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse 响应描述
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType 媒体类型描述
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema JSON Schema描述
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Enum                 []any                     `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+}
+
+// =============================================================================
+// 文档生成
+// =============================================================================
+
+// OpenAPI 根据已注册路由生成OpenAPI 3.1文档
+// 所有路由都会生成路径和路径参数，类型化端点额外生成参数、请求体和响应数据结构，
+// 响应统一包装为Response{code,data,message}
+// 参数 config: 文档配置，仅使用Title、Version、Description
+// 返回值: *OpenAPI 生成的文档
+func (s *Server) OpenAPI(config OpenAPIConfig) *OpenAPI {
+	g := &schemaGenerator{schemas: make(map[string]*OpenAPISchema)}
+	doc := &OpenAPI{
+		OpenAPI: "3.1.0",
+		Info: OpenAPIInfo{
+			Title:       firstNonEmpty(config.Title, s.cfg.Server.Name, "API"),
+			Version:     firstNonEmpty(config.Version, s.cfg.Server.Version, "1.0.0"),
+			Description: firstNonEmpty(config.Description, s.cfg.Server.Desc),
+		},
+		Paths:      make(map[string]map[string]*OpenAPIOperation),
+		Components: OpenAPIComponents{Schemas: g.schemas},
+	}
+
+	endpoints := make(map[string]Endpoint)
+	for _, e := range s.Endpoints() {
+		endpoints[e.Method+" "+e.Path] = e
+	}
+
+	for _, route := range s.Routes() {
+		method := strings.ToLower(route.Method)
+		if !openAPIMethods[method] || route.Path == config.Path || route.Path == config.UIPath {
+			continue
+		}
+
+		path, params := openAPIPath(route.Path)
+		op := &OpenAPIOperation{
+			OperationID: operationID(method, route.Path),
+			Responses:   make(map[string]*OpenAPIResponse),
+		}
+
+		var data *OpenAPISchema
+		if e, ok := endpoints[route.Method+" "+route.Path]; ok {
+			g.requestOperation(op, route.Method, e.Request, params)
+			data = g.schema(e.Response)
+		} else {
+			for _, name := range params {
+				op.Parameters = append(op.Parameters, &OpenAPIParameter{
+					Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"},
+				})
+			}
+			data = &OpenAPISchema{}
+		}
+		op.Responses["200"] = &OpenAPIResponse{
+			Description: "OK",
+			Content:     map[string]*OpenAPIMediaType{"application/json": {Schema: envelopeSchema(data)}},
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][method] = op
+	}
+
+	return doc
+}
+
+// ServeOpenAPI 注册OpenAPI文档和文档页面路由
+// 文档在每次请求时生成，因此包含调用之后注册的路由
+// 参数 config: 文档服务配置，零值字段使用DefaultOpenAPIConfig
+func (s *Server) ServeOpenAPI(config OpenAPIConfig) {
+	if config.Path == "" {
+		config.Path = DefaultOpenAPIConfig.Path
+	}
+	if config.UIPath == "" {
+		config.UIPath = DefaultOpenAPIConfig.UIPath
+	}
+	if config.UI == "" {
+		config.UI = DefaultOpenAPIConfig.UI
+	}
+
+	s.GET(config.Path, func(c *Context) {
+		c.JSON(http.StatusOK, s.OpenAPI(config))
+	})
+
+	if config.UI == "none" {
+		return
+	}
+	page := openAPIPage(config)
+	s.GET(config.UIPath, func(c *Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	})
+}
+
+// openAPIMethods OpenAPI支持的HTTP方法
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// openAPIPath 将gin路由路径转换为OpenAPI路径，如/users/:id转换为/users/{id}
+// 返回值 path: OpenAPI路径
+// 返回值 params: 路径参数名列表
+func openAPIPath(route string) (path string, params []string) {
+	segments := strings.Split(route, "/")
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			params = append(params, seg[1:])
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID 根据方法和路径生成操作ID
+func operationID(method, route string) string {
+	var b strings.Builder
+	b.WriteString(method)
+	for _, seg := range strings.Split(route, "/") {
+		seg = strings.TrimLeft(seg, ":*")
+		if seg != "" {
+			b.WriteByte('_')
+			b.WriteString(seg)
+		}
+	}
+	return b.String()
+}
+
+// envelopeSchema 生成Response响应包装结构
+func envelopeSchema(data *OpenAPISchema) *OpenAPISchema {
+	return &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"code":    {Type: "integer"},
+			"data":    data,
+			"message": {Type: "string"},
+		},
+		Required: []string{"code", "data", "message"},
+	}
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// =============================================================================
+// 数据结构生成
+// =============================================================================
+
+// schemaGenerator 数据结构生成器，具名结构体注册到组件中复用
+type schemaGenerator struct {
+	schemas map[string]*OpenAPISchema
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawJSONType  = reflect.TypeOf(json.RawMessage{})
+	// typePathPattern 匹配类型名中的包路径前缀
+	typePathPattern = regexp.MustCompile(`[A-Za-z0-9_\-./]*[./]`)
+)
+
+// requestOperation 根据请求类型生成参数和请求体
+// uri、header、form标签字段生成路径、请求头、查询参数，其余字段在带请求体的方法中生成请求体
+func (g *schemaGenerator) requestOperation(op *OpenAPIOperation, method string, t reflect.Type, pathParams []string) {
+	declared := make(map[string]bool)
+	body := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+
+	if t = indirectType(t); t.Kind() == reflect.Struct {
+		g.walkFields(t, func(field reflect.StructField) {
+			binding := field.Tag.Get("binding")
+			required := hasRule(binding, "required")
+			for _, loc := range [...]struct{ tag, in string }{{"uri", "path"}, {"header", "header"}, {"form", "query"}} {
+				name := tagName(field.Tag.Get(loc.tag))
+				if name == "" || name == "-" {
+					continue
+				}
+				if loc.in == "path" {
+					declared[name] = true
+					required = true
+				}
+				op.Parameters = append(op.Parameters, &OpenAPIParameter{
+					Name: name, In: loc.in, Required: required, Schema: g.fieldSchema(field),
+				})
+				return
+			}
+
+			name, omit := jsonName(field)
+			if omit {
+				return
+			}
+			body.Properties[name] = g.fieldSchema(field)
+			if required {
+				body.Required = append(body.Required, name)
+			}
+		})
+	}
+
+	// 请求类型未声明的路径参数按字符串处理
+	for _, name := range pathParams {
+		if !declared[name] {
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"},
+			})
+		}
+	}
+
+	if len(body.Properties) > 0 && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch) {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: len(body.Required) > 0,
+			Content:  map[string]*OpenAPIMediaType{"application/json": {Schema: body}},
+		}
+	}
+}
+
+// schema 生成类型对应的数据结构
+func (g *schemaGenerator) schema(t reflect.Type) *OpenAPISchema {
+	t = indirectType(t)
+
+	switch t {
+	case timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case rawJSONType:
+		return &OpenAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+// structSchema 生成结构体数据结构，具名结构体以$ref引用组件
+func (g *schemaGenerator) structSchema(t reflect.Type) *OpenAPISchema {
+	name := schemaName(t)
+	if name != "" {
+		if _, ok := g.schemas[name]; ok {
+			return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+		}
+		// 先占位，防止递归类型无限展开
+		g.schemas[name] = &OpenAPISchema{}
+	}
+
+	s := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	g.walkFields(t, func(field reflect.StructField) {
+		jsonField, omit := jsonName(field)
+		if omit {
+			return
+		}
+		s.Properties[jsonField] = g.fieldSchema(field)
+		if hasRule(field.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, jsonField)
+		}
+	})
+
+	if name == "" {
+		return s
+	}
+	*g.schemas[name] = *s
+	return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// fieldSchema 生成字段数据结构，应用description标签和binding验证规则
+func (g *schemaGenerator) fieldSchema(field reflect.StructField) *OpenAPISchema {
+	s := g.schema(field.Type)
+	description := field.Tag.Get("description")
+	rules := field.Tag.Get("binding")
+	if s.Ref != "" || (description == "" && rules == "") {
+		return s
+	}
+
+	// 复制一份，避免修改共享的数据结构
+	copied := *s
+	s = &copied
+	s.Description = description
+	for _, rule := range strings.Split(rules, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "gte", "max", "lte":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			isMin := name == "min" || name == "gte"
+			if s.Type == "string" {
+				length := int(n)
+				if isMin {
+					s.MinLength = &length
+				} else {
+					s.MaxLength = &length
+				}
+			} else if s.Type == "integer" || s.Type == "number" {
+				if isMin {
+					s.Minimum = &n
+				} else {
+					s.Maximum = &n
+				}
+			}
+		}
+	}
+	return s
+}
+
+// walkFields 遍历结构体的导出字段，展开匿名嵌入结构体
+func (g *schemaGenerator) walkFields(t reflect.Type, fn func(reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := indirectType(field.Type)
+			if ft.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+				g.walkFields(ft, fn)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fn(field)
+	}
+}
+
+// jsonName 获取字段的JSON名称
+// 返回值 name: JSON字段名
+// 返回值 omit: 字段是否被忽略
+func jsonName(field reflect.StructField) (name string, omit bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if name = tagName(tag); name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+// schemaName 生成组件名称，如PageResp[[]example.com/app.User]转换为PageResp_List_User
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return ""
+	}
+	name = typePathPattern.ReplaceAllString(name, "")
+	return strings.NewReplacer("[]", "List_", "[", "_", ",", "_", "]", "", "*", "", " ", "").Replace(name)
+}
+
+// hasRule 判断binding标签中是否包含指定规则
+func hasRule(rules, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// indirectType 获取指针指向的基础类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// =============================================================================
+// 文档页面
+// =============================================================================
+
+// openAPIPageTemplate 文档页面模板
+var openAPIPageTemplate = template.Must(template.New("openapi").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+{{- if eq .UI "redoc"}}
+</head>
+<body>
+  <redoc spec-url="{{.Spec}}"></redoc>
+  <script src="{{.Assets}}/bundles/redoc.standalone.js"></script>
+</body>
+{{- else}}
+  <link rel="stylesheet" href="{{.Assets}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{.Assets}}/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: "{{.Spec}}", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+{{- end}}
+</html>
+`))
+
+// openAPIPage 渲染文档页面
+// 未设置AssetsURL时引用CDN上的资源
+func openAPIPage(config OpenAPIConfig) []byte {
+	assets := config.AssetsURL
+	if assets == "" {
+		if config.UI == "redoc" {
+			assets = "https://cdn.jsdelivr.net/npm/redoc@2"
+		} else {
+			assets = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5"
+		}
+	}
+
+	var b strings.Builder
+	_ = openAPIPageTemplate.Execute(&b, map[string]string{
+		"Title":  firstNonEmpty(config.Title, "API Docs"),
+		"UI":     config.UI,
+		"Spec":   config.Path,
+		"Assets": strings.TrimRight(assets, "/"),
+	})
+	return []byte(b.String())
+}
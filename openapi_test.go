@@ -0,0 +1,83 @@
+package chi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type docUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name" binding:"required,max=32"`
+	Role string `json:"role,omitempty" binding:"oneof=admin user"`
+}
+
+type listUsersReq struct {
+	Page int `form:"page" binding:"min=1"`
+}
+
+type createUserReq struct {
+	OrgID string `uri:"org"`
+	Name  string `json:"name" binding:"required"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	s := newTestServer()
+	s.cfg.Server.Name = "user-service"
+	api := s.Group("/api")
+	GETT(api, "/users", func(c *Context, req listUsersReq) (*PageResp[[]docUser], error) {
+		return nil, nil
+	})
+	POSTT(api, "/orgs/:org/users", func(c *Context, req createUserReq) (docUser, error) {
+		return docUser{}, nil
+	})
+	s.GET("/files/*path", func(c *Context) {})
+	s.ServeOpenAPI(OpenAPIConfig{})
+
+	w := doRequest(s, http.MethodGet, "/openapi.json", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc OpenAPI
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+	assert.Equal(t, "user-service", doc.Info.Title)
+	assert.NotContains(t, doc.Paths, "/openapi.json")
+	assert.NotContains(t, doc.Paths, "/docs")
+
+	list := doc.Paths["/api/users"]["get"]
+	require.NotNil(t, list)
+	require.Len(t, list.Parameters, 1)
+	assert.Equal(t, "query", list.Parameters[0].In)
+	assert.Equal(t, float64(1), *list.Parameters[0].Schema.Minimum)
+	data := list.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	assert.Equal(t, "#/components/schemas/PageResp_List_docUser", data.Ref)
+
+	page := doc.Components.Schemas["PageResp_List_docUser"]
+	require.NotNil(t, page)
+	assert.Equal(t, "#/components/schemas/docUser", page.Properties["list"].Items.Ref)
+
+	user := doc.Components.Schemas["docUser"]
+	require.NotNil(t, user)
+	assert.Equal(t, []string{"name"}, user.Required)
+	assert.Equal(t, 32, *user.Properties["name"].MaxLength)
+	assert.Equal(t, []any{"admin", "user"}, user.Properties["role"].Enum)
+
+	create := doc.Paths["/api/orgs/{org}/users"]["post"]
+	require.NotNil(t, create)
+	assert.Equal(t, "path", create.Parameters[0].In)
+	assert.True(t, create.Parameters[0].Required)
+	body := create.RequestBody.Content["application/json"].Schema
+	assert.Contains(t, body.Properties, "name")
+	assert.NotContains(t, body.Properties, "OrgID")
+
+	files := doc.Paths["/files/{path}"]["get"]
+	require.NotNil(t, files)
+	assert.Equal(t, "path", files.Parameters[0].Name)
+
+	w = doRequest(s, http.MethodGet, "/docs", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "swagger-ui")
+}
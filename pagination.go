@@ -0,0 +1,393 @@
+package chi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// 排序方向
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// PageConfig 分页配置
+type PageConfig struct {
+	// DefaultSize 默认每页条数
+	DefaultSize int
+	// MaxSize 每页条数上限，超出时按上限处理
+	MaxSize int
+	// SortFields 允许排序的字段白名单，为空时只能按DefaultSort排序
+	SortFields []string
+	// DefaultSort 默认排序字段，为空时使用KeyField
+	DefaultSort string
+	// DefaultOrder 默认排序方向，asc或desc
+	DefaultOrder string
+	// KeyField 唯一键字段，游标分页时作为排序字段值相同时的第二排序条件
+	KeyField string
+	// Secret 游标签名密钥，为空时使用进程级随机密钥，游标在重启或多实例间失效
+	Secret []byte
+}
+
+// DefaultPageConfig 默认分页配置
+var DefaultPageConfig = PageConfig{
+	DefaultSize:  20,
+	MaxSize:      100,
+	DefaultOrder: OrderDesc,
+	KeyField:     "id",
+}
+
+// PageQuery 分页查询参数
+// 支持两种模式：page/size偏移分页，cursor/limit游标（keyset）分页。
+// 请求携带cursor或limit时使用游标分页
+type PageQuery struct {
+	// Page 页码，从1开始
+	Page int `form:"page" json:"page,omitempty"`
+	// Size 每页条数
+	Size int `form:"size" json:"size,omitempty"`
+	// Cursor 游标，取自上一次响应的next或prev
+	Cursor string `form:"cursor" json:"cursor,omitempty"`
+	// Limit 游标分页每页条数
+	Limit int `form:"limit" json:"limit,omitempty"`
+	// Sort 排序字段
+	Sort string `form:"sort" json:"sort,omitempty"`
+	// Order 排序方向，asc或desc
+	Order string `form:"order" json:"order,omitempty"`
+
+	// config 分页配置
+	config PageConfig
+	// cursor 解析后的游标
+	cursor *pageCursor
+}
+
+// CursorResp 游标分页响应
+type CursorResp[T any] struct {
+	List T `json:"list"`
+	// Next 下一页游标，没有下一页时为空
+	Next string `json:"next,omitempty"`
+	// Prev 上一页游标，没有上一页时为空
+	Prev string `json:"prev,omitempty"`
+	// HasMore 是否有下一页
+	HasMore bool `json:"has_more"`
+}
+
+// pageCursor 游标内容
+type pageCursor struct {
+	// Sort 生成游标时的排序字段
+	Sort string `json:"s"`
+	// Desc 生成游标时的排序方向
+	Desc bool `json:"d,omitempty"`
+	// Value 边界记录的排序字段值
+	Value any `json:"v,omitempty"`
+	// Key 边界记录的唯一键值
+	Key any `json:"k"`
+	// Backward 是否向前翻页
+	Backward bool `json:"b,omitempty"`
+}
+
+// cursorTimeKey 游标中时间值的类型标记
+const cursorTimeKey = "$t"
+
+// 分页参数错误
+var (
+	errInvalidCursor = errors.New("invalid page cursor")
+	errInvalidSort   = errors.New("invalid sort field")
+	errInvalidOrder  = errors.New("invalid sort order")
+)
+
+// defaultCursorSecret 未配置密钥时使用的进程级随机密钥
+var defaultCursorSecret = func() []byte {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}()
+
+// =============================================================================
+// 参数绑定
+// =============================================================================
+
+// BindPage 绑定并校验分页查询参数
+// 参数 config: 分页配置，不传时使用DefaultPageConfig
+// 返回值: *PageQuery 分页参数
+// 返回值: error 参数错误，为ErrBinding派生的错误
+func (c *Context) BindPage(config ...PageConfig) (*PageQuery, error) {
+	q := &PageQuery{}
+	if err := c.ShouldBindQuery(q); err != nil {
+		return nil, bindingError(err)
+	}
+	cfg := DefaultPageConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if err := q.Normalize(cfg); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Normalize 按分页配置补全默认值、限制条数并校验排序字段和游标
+// 用于PageQuery嵌入在其他请求结构中的场景
+// 参数 config: 分页配置
+// 返回值: error 参数错误，为ErrBinding派生的错误
+func (q *PageQuery) Normalize(config PageConfig) error {
+	if config.DefaultSize <= 0 {
+		config.DefaultSize = DefaultPageConfig.DefaultSize
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultPageConfig.MaxSize
+	}
+	if config.KeyField == "" {
+		config.KeyField = DefaultPageConfig.KeyField
+	}
+	if config.DefaultSort == "" {
+		config.DefaultSort = config.KeyField
+	}
+	if config.DefaultOrder == "" {
+		config.DefaultOrder = DefaultPageConfig.DefaultOrder
+	}
+	if len(config.Secret) == 0 {
+		config.Secret = defaultCursorSecret
+	}
+	q.config = config
+
+	if q.Sort == "" {
+		q.Sort = config.DefaultSort
+	} else if q.Sort != config.DefaultSort && !slices.Contains(config.SortFields, q.Sort) {
+		return ErrBinding.Wrap(errInvalidSort).WithDetails(map[string]any{"sort": q.Sort})
+	}
+	if q.Order == "" {
+		q.Order = config.DefaultOrder
+	}
+	q.Order = strings.ToLower(q.Order)
+	if q.Order != OrderAsc && q.Order != OrderDesc {
+		return ErrBinding.Wrap(errInvalidOrder).WithDetails(map[string]any{"order": q.Order})
+	}
+
+	if q.IsCursor() {
+		q.Page, q.Size = 0, 0
+		q.Limit = clampSize(q.Limit, config)
+		if q.Cursor != "" {
+			cursor, err := decodeCursor(q.Cursor, config.Secret)
+			if err != nil || cursor.Sort != q.Sort || cursor.Desc != (q.Order == OrderDesc) {
+				return ErrBinding.Wrap(errInvalidCursor).WithMessage("无效的分页游标")
+			}
+			q.cursor = cursor
+		}
+		return nil
+	}
+
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	q.Size = clampSize(q.Size, config)
+	return nil
+}
+
+// clampSize 补全默认条数并限制上限
+func clampSize(size int, config PageConfig) int {
+	if size <= 0 {
+		return config.DefaultSize
+	}
+	return min(size, config.MaxSize)
+}
+
+// =============================================================================
+// 查询参数
+// 供pkg/database和pkg/mongo的分页适配器使用
+// =============================================================================
+
+// IsCursor 是否为游标分页
+func (q *PageQuery) IsCursor() bool {
+	return q.Cursor != "" || q.Limit > 0
+}
+
+// Offset 查询偏移量，游标分页时为0
+func (q *PageQuery) Offset() int {
+	if q.IsCursor() {
+		return 0
+	}
+	return (q.Page - 1) * q.Size
+}
+
+// FetchSize 查询条数
+// 游标分页时多查询一条用于判断是否还有更多数据
+func (q *PageQuery) FetchSize() int {
+	if q.IsCursor() {
+		return q.Limit + 1
+	}
+	return q.Size
+}
+
+// SortField 排序字段
+func (q *PageQuery) SortField() string {
+	return q.Sort
+}
+
+// KeyField 唯一键字段
+func (q *PageQuery) KeyField() string {
+	if q.config.KeyField == "" {
+		return DefaultPageConfig.KeyField
+	}
+	return q.config.KeyField
+}
+
+// Descending 实际查询是否为降序
+// 游标向前翻页时与请求的排序方向相反，结果由NewCursorResp恢复顺序
+func (q *PageQuery) Descending() bool {
+	desc := q.Order == OrderDesc
+	if q.cursor != nil && q.cursor.Backward {
+		return !desc
+	}
+	return desc
+}
+
+// Seek 游标位置
+// 返回值: sortValue 边界记录的排序字段值
+// 返回值: keyValue 边界记录的唯一键值
+// 返回值: ok 是否存在游标，为false时从第一条开始查询
+func (q *PageQuery) Seek() (sortValue, keyValue any, ok bool) {
+	if q.cursor == nil {
+		return nil, nil, false
+	}
+	return q.cursor.Value, q.cursor.Key, true
+}
+
+// =============================================================================
+// 游标响应
+// =============================================================================
+
+// NewCursorResp 根据查询结果创建游标分页响应
+// 结果应按PageQuery查询（游标分页时多查询一条），函数会截断多余记录并生成前后游标
+// 参数 q: 分页参数
+// 参数 items: 查询结果
+// 参数 position: 返回记录的排序字段值和唯一键值
+// 返回值: *CursorResp[[]E] 游标分页响应
+func NewCursorResp[E any](q *PageQuery, items []E, position func(E) (sortValue, keyValue any)) *CursorResp[[]E] {
+	if !q.IsCursor() {
+		return &CursorResp[[]E]{List: items}
+	}
+
+	backward := q.cursor != nil && q.cursor.Backward
+	more := len(items) > q.Limit
+	if more {
+		items = items[:q.Limit]
+	}
+	if backward {
+		slices.Reverse(items)
+	}
+
+	resp := &CursorResp[[]E]{List: items}
+	if len(items) == 0 {
+		return resp
+	}
+
+	// 向后翻页时是否有下一页取决于是否多查到记录；向前翻页时来源页必然存在
+	hasNext := more || backward
+	hasPrev := q.cursor != nil && (!backward || more)
+	if hasNext {
+		sortValue, keyValue := position(items[len(items)-1])
+		resp.Next = q.encodeCursor(sortValue, keyValue, false)
+	}
+	if hasPrev {
+		sortValue, keyValue := position(items[0])
+		resp.Prev = q.encodeCursor(sortValue, keyValue, true)
+	}
+	resp.HasMore = hasNext
+	return resp
+}
+
+// encodeCursor 生成签名游标
+func (q *PageQuery) encodeCursor(sortValue, keyValue any, backward bool) string {
+	cursor := pageCursor{
+		Sort:     q.Sort,
+		Desc:     q.Order == OrderDesc,
+		Key:      encodeCursorValue(keyValue),
+		Backward: backward,
+	}
+	if q.Sort != q.KeyField() {
+		cursor.Value = encodeCursorValue(sortValue)
+	}
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	secret := q.config.Secret
+	if len(secret) == 0 {
+		secret = defaultCursorSecret
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(signCursor(payload, secret))
+}
+
+// decodeCursor 校验签名并解析游标
+func decodeCursor(s string, secret []byte) (*pageCursor, error) {
+	enc := base64.RawURLEncoding
+	payloadPart, sigPart, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, signCursor(payload, secret)) {
+		return nil, errInvalidCursor
+	}
+
+	var cursor pageCursor
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	cursor.Value = decodeCursorValue(cursor.Value)
+	cursor.Key = decodeCursorValue(cursor.Key)
+	return &cursor, nil
+}
+
+// signCursor 计算游标签名
+func signCursor(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// encodeCursorValue 编码游标值，时间值附带类型标记以便解析时还原
+func encodeCursorValue(v any) any {
+	if t, ok := v.(time.Time); ok {
+		return map[string]string{cursorTimeKey: t.Format(time.RFC3339Nano)}
+	}
+	return v
+}
+
+// decodeCursorValue 还原游标值，整数还原为int64，浮点数还原为float64
+func decodeCursorValue(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any:
+		if s, ok := val[cursorTimeKey].(string); ok && len(val) == 1 {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+	}
+	return v
+}
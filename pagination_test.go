@@ -0,0 +1,114 @@
+package chi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pageItem struct {
+	ID    int64
+	Score int64
+}
+
+// fetchPage 模拟按PageQuery在内存数据上执行keyset查询
+func fetchPage(q *PageQuery, items []pageItem) []pageItem {
+	desc := q.Descending()
+	sortValue, keyValue, seek := q.Seek()
+	ordered := make([]pageItem, len(items))
+	copy(ordered, items)
+	if desc {
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+
+	var result []pageItem
+	for _, item := range ordered {
+		if seek {
+			after := item.Score > sortValue.(int64) || (item.Score == sortValue.(int64) && item.ID > keyValue.(int64))
+			if desc {
+				after = item.Score < sortValue.(int64) || (item.Score == sortValue.(int64) && item.ID < keyValue.(int64))
+			}
+			if !after {
+				continue
+			}
+		}
+		result = append(result, item)
+		if len(result) == q.FetchSize() {
+			break
+		}
+	}
+	return result
+}
+
+func TestPageQuery(t *testing.T) {
+	config := PageConfig{
+		DefaultSize: 10,
+		MaxSize:     50,
+		SortFields:  []string{"score"},
+		Secret:      []byte("secret"),
+	}
+
+	bind := func(query string) (*PageQuery, error) {
+		s := newTestServer()
+		var q *PageQuery
+		var err error
+		s.GET("/items", func(c *Context) { q, err = c.BindPage(config) })
+		doRequest(s, http.MethodGet, "/items?"+query, "", nil)
+		return q, err
+	}
+
+	t.Run("Offset", func(t *testing.T) {
+		q, err := bind("page=3&size=500")
+		require.NoError(t, err)
+		assert.False(t, q.IsCursor())
+		assert.Equal(t, 50, q.Size)
+		assert.Equal(t, 100, q.Offset())
+		assert.Equal(t, "id", q.SortField())
+		assert.True(t, q.Descending())
+	})
+
+	t.Run("InvalidSort", func(t *testing.T) {
+		_, err := bind("sort=password")
+		assert.ErrorIs(t, err, ErrBinding)
+		_, err = bind("order=random")
+		assert.ErrorIs(t, err, ErrBinding)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		var items []pageItem
+		for i := int64(1); i <= 7; i++ {
+			items = append(items, pageItem{ID: i, Score: i / 2})
+		}
+		position := func(item pageItem) (any, any) { return item.Score, item.ID }
+
+		q, err := bind("limit=3&sort=score&order=asc")
+		require.NoError(t, err)
+		first := NewCursorResp(q, fetchPage(q, items), position)
+		assert.Equal(t, []pageItem{items[0], items[1], items[2]}, first.List)
+		assert.True(t, first.HasMore)
+		assert.Empty(t, first.Prev)
+
+		q, err = bind("limit=3&sort=score&order=asc&cursor=" + url.QueryEscape(first.Next))
+		require.NoError(t, err)
+		second := NewCursorResp(q, fetchPage(q, items), position)
+		assert.Equal(t, []pageItem{items[3], items[4], items[5]}, second.List)
+		assert.NotEmpty(t, second.Prev)
+
+		q, err = bind("limit=3&sort=score&order=asc&cursor=" + url.QueryEscape(second.Prev))
+		require.NoError(t, err)
+		back := NewCursorResp(q, fetchPage(q, items), position)
+		assert.Equal(t, first.List, back.List)
+		assert.Empty(t, back.Prev)
+		assert.NotEmpty(t, back.Next)
+
+		_, err = bind("limit=3&sort=score&order=desc&cursor=" + url.QueryEscape(first.Next))
+		assert.ErrorIs(t, err, ErrBinding)
+		_, err = bind("limit=3&sort=score&order=asc&cursor=" + url.QueryEscape(first.Next+"x"))
+		assert.ErrorIs(t, err, ErrBinding)
+	})
+}
@@ -0,0 +1,89 @@
+package authz
+
+import (
+	"chi"
+)
+
+// =============================================================================
+// chi授权器
+// =============================================================================
+
+// 授权使用的上下文键，认证中间件负责写入
+var (
+	// RolesKey 当前用户的角色
+	RolesKey = chi.NewKey[[]string]("roles")
+	// TenantIDKey 当前用户所属租户
+	TenantIDKey = chi.NewKey[string]("tenant_id")
+)
+
+// AuthorizerConfig 授权器配置
+type AuthorizerConfig struct {
+	// Subject 获取当前请求的访问主体，返回nil表示未登录，
+	// 默认从chi.UserIDKey、RolesKey和TenantIDKey读取
+	Subject func(c *chi.Context) *Subject
+	// Resource 获取被访问资源的属性，默认使用路径参数，如/users/:owner_id
+	Resource func(c *chi.Context) (map[string]any, error)
+}
+
+// authorizer 基于策略引擎的chi授权器
+type authorizer struct {
+	engine *Engine
+	config AuthorizerConfig
+}
+
+// Authorizer 创建chi授权器，通过chi.Server.SetAuthorizer使用
+// 未登录时返回chi.ErrUnauthorized，缺少权限时返回chi.ErrForbidden
+// 参数 config: 可选的授权器配置
+// 返回值: chi.Authorizer 授权器
+func (e *Engine) Authorizer(config ...AuthorizerConfig) chi.Authorizer {
+	var cfg AuthorizerConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Subject == nil {
+		cfg.Subject = defaultSubject
+	}
+	if cfg.Resource == nil {
+		cfg.Resource = pathResource
+	}
+	return &authorizer{engine: e, config: cfg}
+}
+
+// Authorize 实现chi.Authorizer接口
+func (a *authorizer) Authorize(c *chi.Context, permissions []string) error {
+	subject := a.config.Subject(c)
+	if subject == nil {
+		return chi.ErrUnauthorized
+	}
+	resource, err := a.config.Resource(c)
+	if err != nil {
+		return err
+	}
+	for _, p := range permissions {
+		if !a.engine.Allowed(&Request{Subject: subject, Permission: p, Resource: resource}) {
+			return chi.ErrForbidden.WithDetails(map[string]any{"permission": p})
+		}
+	}
+	return nil
+}
+
+// defaultSubject 从上下文键读取访问主体
+func defaultSubject(c *chi.Context) *Subject {
+	id, _ := chi.GetValue(c, chi.UserIDKey)
+	if id == "" {
+		return nil
+	}
+	roles, _ := chi.GetValue(c, RolesKey)
+	tenantID, _ := chi.GetValue(c, TenantIDKey)
+	return &Subject{ID: id, Roles: roles, TenantID: tenantID}
+}
+
+// pathResource 使用路径参数作为资源属性
+func pathResource(c *chi.Context) (map[string]any, error) {
+	params := c.Context.Params
+	resource := make(map[string]any, len(params))
+	for _, p := range params {
+		resource[p.Key] = p.Value
+	}
+	return resource, nil
+}
@@ -0,0 +1,112 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chi"
+	"chi/pkg/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pkg/database.Client可以直接作为策略来源
+var _ DBSource = (*database.Client)(nil)
+
+const testPolicy = `
+roles:
+  - name: viewer
+    permissions:
+      - order:read
+  - name: customer
+    inherits: [viewer]
+    permissions:
+      - name: order:cancel
+        conditions: [owner]
+  - name: tenant-admin
+    permissions:
+      - name: "order:*"
+        conditions: [tenant]
+  - name: admin
+    permissions: ["*"]
+`
+
+func loadTestPolicy(t *testing.T) *Policy {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+	policy, err := LoadFile(path)
+	require.NoError(t, err)
+	return policy
+}
+
+func TestEngine(t *testing.T) {
+	policy := loadTestPolicy(t)
+	require.Len(t, policy.Roles, 4)
+	assert.Equal(t, Permission{Name: "order:read"}, policy.Roles[0].Permissions[0])
+	assert.Equal(t, []string{"owner"}, policy.Roles[1].Permissions[0].Conditions)
+
+	e := NewEngine(policy)
+	customer := &Subject{ID: "u1", Roles: []string{"customer"}}
+	own := map[string]any{"owner_id": "u1"}
+	other := map[string]any{"owner_id": "u2", "tenant_id": "t1"}
+
+	// 继承的权限
+	assert.NoError(t, e.Check(customer, nil, "order:read"))
+	// owner条件
+	assert.NoError(t, e.Check(customer, own, "order:cancel"))
+	assert.Error(t, e.Check(customer, other, "order:cancel"))
+	assert.Error(t, e.Check(customer, own, "order:delete"))
+
+	// tenant条件和通配
+	tenantAdmin := &Subject{ID: "u3", Roles: []string{"tenant-admin"}, TenantID: "t1"}
+	assert.NoError(t, e.Check(tenantAdmin, other, "order:delete", "order:read"))
+	assert.Error(t, e.Check(tenantAdmin, map[string]any{"tenant_id": "t2"}, "order:read"))
+	assert.Error(t, e.Check(tenantAdmin, other, "user:read"))
+
+	assert.NoError(t, e.Check(&Subject{ID: "root", Roles: []string{"admin"}}, nil, "user:delete"))
+	assert.Error(t, e.Check(&Subject{ID: "u4", Roles: []string{"unknown"}}, nil, "order:read"))
+
+	// 未注册的条件视为不满足
+	e.SetPolicy(&Policy{Roles: []Role{{Name: "vip", Permissions: []Permission{{Name: "order:read", Conditions: []string{"vip"}}}}}})
+	vip := &Subject{ID: "u5", Roles: []string{"vip"}, Attributes: map[string]any{"level": 3}}
+	assert.Error(t, e.Check(vip, nil, "order:read"))
+	e.RegisterCondition("vip", func(req *Request) bool { return req.Subject.Attributes["level"] == 3 })
+	assert.NoError(t, e.Check(vip, nil, "order:read"))
+}
+
+func TestAuthorizer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := chi.New()
+	s.UseHTTPStatus(true)
+	s.SetAuthorizer(NewEngine(loadTestPolicy(t)).Authorizer())
+
+	// 模拟认证中间件
+	s.Use(func(c *chi.Context) {
+		if id := c.GetHeader("X-User"); id != "" {
+			chi.SetValue(c, chi.UserIDKey, id)
+			chi.SetValue(c, RolesKey, []string{"customer"})
+		}
+		c.Next()
+	})
+	s.POST("/users/:owner_id/orders/:id/cancel", func(c *chi.Context) {
+		c.Status(http.StatusNoContent)
+	}, chi.Require("order:cancel"))
+
+	do := func(path, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusNoContent, do("/users/u1/orders/9/cancel", "u1").Code)
+	w := do("/users/u2/orders/9/cancel", "u1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"permission":"order:cancel"`)
+	assert.Equal(t, http.StatusUnauthorized, do("/users/u1/orders/9/cancel", "").Code)
+}
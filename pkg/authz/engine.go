@@ -0,0 +1,198 @@
+package authz
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// Subject 访问主体，通常是当前登录用户
+type Subject struct {
+	// ID 用户ID
+	ID string
+	// Roles 用户拥有的角色
+	Roles []string
+	// TenantID 用户所属租户
+	TenantID string
+	// Attributes 其他属性，供自定义条件使用
+	Attributes map[string]any
+}
+
+// Request 授权请求
+type Request struct {
+	// Subject 访问主体
+	Subject *Subject
+	// Permission 需要的权限
+	Permission string
+	// Resource 被访问资源的属性，如owner_id、tenant_id
+	Resource map[string]any
+}
+
+// Condition 属性条件，返回true表示满足
+type Condition func(req *Request) bool
+
+// 内置条件名称
+const (
+	// ConditionOwner 资源的owner_id等于用户ID
+	ConditionOwner = "owner"
+	// ConditionTenant 资源的tenant_id等于用户的租户ID
+	ConditionTenant = "tenant"
+)
+
+// Engine 策略引擎
+// 基于角色授予权限（RBAC），权限可以附带属性条件（ABAC），如只能访问自己的资源
+type Engine struct {
+	mu         sync.RWMutex
+	roles      map[string]Role
+	conditions map[string]Condition
+}
+
+// =============================================================================
+// 构造函数
+// =============================================================================
+
+// NewEngine 创建策略引擎，内置owner和tenant条件
+// 参数 policy: 授权策略，为nil时没有任何权限
+// 返回值: *Engine 策略引擎
+func NewEngine(policy *Policy) *Engine {
+	e := &Engine{
+		conditions: map[string]Condition{
+			ConditionOwner:  ownerCondition,
+			ConditionTenant: tenantCondition,
+		},
+	}
+	e.SetPolicy(policy)
+	return e
+}
+
+// SetPolicy 替换授权策略，可用于热加载
+// 参数 policy: 授权策略
+func (e *Engine) SetPolicy(policy *Policy) {
+	roles := make(map[string]Role)
+	if policy != nil {
+		for _, r := range policy.Roles {
+			roles[r.Name] = r
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.roles = roles
+}
+
+// RegisterCondition 注册属性条件，同名条件会被替换
+// 参数 name: 条件名称，在策略的conditions中引用
+// 参数 condition: 条件函数
+func (e *Engine) RegisterCondition(name string, condition Condition) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.conditions[name] = condition
+}
+
+// =============================================================================
+// 授权检查
+// =============================================================================
+
+// Allowed 检查授权请求
+// 主体的任一角色（包括继承的角色）拥有匹配的权限且该权限的条件全部满足时允许；
+// 引用了未注册条件的权限视为不满足
+// 参数 req: 授权请求
+// 返回值: bool 是否允许
+func (e *Engine) Allowed(req *Request) bool {
+	if req.Subject == nil {
+		return false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, role := range e.expandRoles(req.Subject.Roles) {
+		for _, p := range role.Permissions {
+			if matchPermission(p.Name, req.Permission) && e.satisfied(p.Conditions, req) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check 检查主体是否拥有所有权限
+// 参数 subject: 访问主体
+// 参数 resource: 被访问资源的属性
+// 参数 permissions: 需要的权限
+// 返回值: error 缺少权限时的错误信息
+func (e *Engine) Check(subject *Subject, resource map[string]any, permissions ...string) error {
+	for _, p := range permissions {
+		if !e.Allowed(&Request{Subject: subject, Permission: p, Resource: resource}) {
+			return fmt.Errorf("permission denied: %s", p)
+		}
+	}
+	return nil
+}
+
+// expandRoles 展开角色继承关系，忽略未定义的角色和循环继承
+func (e *Engine) expandRoles(names []string) []Role {
+	var result []Role
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		role, ok := e.roles[name]
+		if !ok {
+			return
+		}
+		result = append(result, role)
+		for _, parent := range role.Inherits {
+			visit(parent)
+		}
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return result
+}
+
+// satisfied 检查条件是否全部满足
+func (e *Engine) satisfied(conditions []string, req *Request) bool {
+	for _, name := range conditions {
+		condition, ok := e.conditions[name]
+		if !ok || !condition(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPermission 检查授予的权限是否匹配需要的权限
+// "*"匹配所有权限，"order:*"匹配"order:read"等
+func matchPermission(granted, required string) bool {
+	if granted == "*" || granted == required {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(granted, "*")
+	return ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(required, prefix)
+}
+
+// ownerCondition 资源的owner_id等于用户ID
+func ownerCondition(req *Request) bool {
+	return req.Subject.ID != "" && attribute(req.Resource, "owner_id") == req.Subject.ID
+}
+
+// tenantCondition 资源的tenant_id等于用户的租户ID
+func tenantCondition(req *Request) bool {
+	return req.Subject.TenantID != "" && attribute(req.Resource, "tenant_id") == req.Subject.TenantID
+}
+
+// attribute 获取资源属性的字符串形式
+func attribute(resource map[string]any, key string) string {
+	v, ok := resource[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,182 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// 策略定义
+// =============================================================================
+
+// Policy 授权策略
+type Policy struct {
+	// Roles 角色列表
+	Roles []Role `json:"roles" yaml:"roles"`
+}
+
+// Role 角色
+type Role struct {
+	// Name 角色名称
+	Name string `json:"name" yaml:"name"`
+	// Inherits 继承的角色，拥有被继承角色的所有权限
+	Inherits []string `json:"inherits,omitempty" yaml:"inherits,omitempty"`
+	// Permissions 角色拥有的权限
+	Permissions []Permission `json:"permissions" yaml:"permissions"`
+}
+
+// Permission 权限
+// 配置文件中可以直接写权限名称字符串，等价于没有条件的权限
+type Permission struct {
+	// Name 权限名称，如"order:read"，支持"order:*"和"*"通配
+	Name string `json:"name" yaml:"name"`
+	// Conditions 属性条件，全部满足时权限才生效，如"owner"、"tenant"
+	Conditions []string `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// UnmarshalJSON 支持字符串形式的权限
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = Permission{Name: name}
+		return nil
+	}
+	type permission Permission
+	return json.Unmarshal(data, (*permission)(p))
+}
+
+// UnmarshalYAML 支持字符串形式的权限
+func (p *Permission) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*p = Permission{Name: node.Value}
+		return nil
+	}
+	type permission Permission
+	return node.Decode((*permission)(p))
+}
+
+// =============================================================================
+// 策略加载
+// =============================================================================
+
+// LoadFile 从文件加载授权策略
+// 参数 path: 策略文件路径，.json文件按JSON解析，其他按YAML解析
+// 返回值: *Policy 授权策略
+// 返回值: error 读取或解析失败时的错误信息
+func LoadFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	policy := &Policy{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, policy)
+	} else {
+		err = yaml.Unmarshal(data, policy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return policy, nil
+}
+
+// DBSource 数据库来源，pkg/database.Client实现了该接口
+type DBSource interface {
+	DB() *gorm.DB
+}
+
+// RoleRecord 角色表记录
+type RoleRecord struct {
+	// Name 角色名称
+	Name string `gorm:"primaryKey;size:64"`
+	// Inherits 继承的角色，逗号分隔
+	Inherits string `gorm:"size:255"`
+}
+
+// TableName 角色表名
+func (RoleRecord) TableName() string {
+	return "authz_roles"
+}
+
+// PermissionRecord 角色权限表记录
+type PermissionRecord struct {
+	ID uint `gorm:"primaryKey"`
+	// Role 角色名称
+	Role string `gorm:"size:64;index"`
+	// Permission 权限名称
+	Permission string `gorm:"size:128"`
+	// Conditions 属性条件，逗号分隔
+	Conditions string `gorm:"size:255"`
+}
+
+// TableName 角色权限表名
+func (PermissionRecord) TableName() string {
+	return "authz_permissions"
+}
+
+// Migrate 创建或更新策略表
+// 参数 ctx: 上下文
+// 参数 source: 数据库客户端，如pkg/database.Client
+// 返回值: error 迁移失败时的错误信息
+func Migrate(ctx context.Context, source DBSource) error {
+	if err := source.DB().WithContext(ctx).AutoMigrate(&RoleRecord{}, &PermissionRecord{}); err != nil {
+		return fmt.Errorf("failed to migrate policy tables: %w", err)
+	}
+	return nil
+}
+
+// LoadDatabase 从数据库的authz_roles和authz_permissions表加载授权策略
+// 权限表中出现但角色表中没有的角色同样有效
+// 参数 ctx: 上下文
+// 参数 source: 数据库客户端，如pkg/database.Client
+// 返回值: *Policy 授权策略
+// 返回值: error 查询失败时的错误信息
+func LoadDatabase(ctx context.Context, source DBSource) (*Policy, error) {
+	db := source.DB().WithContext(ctx)
+	var roles []RoleRecord
+	if err := db.Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	var permissions []PermissionRecord
+	if err := db.Order("id").Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	policy := &Policy{}
+	index := make(map[string]int)
+	role := func(name string) *Role {
+		i, ok := index[name]
+		if !ok {
+			i = len(policy.Roles)
+			index[name] = i
+			policy.Roles = append(policy.Roles, Role{Name: name})
+		}
+		return &policy.Roles[i]
+	}
+	for _, r := range roles {
+		role(r.Name).Inherits = splitList(r.Inherits)
+	}
+	for _, p := range permissions {
+		r := role(p.Role)
+		r.Permissions = append(r.Permissions, Permission{Name: p.Permission, Conditions: splitList(p.Conditions)})
+	}
+	return policy, nil
+}
+
+// splitList 拆分逗号分隔的列表
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
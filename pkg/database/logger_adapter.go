@@ -136,6 +136,7 @@ func (a *DatabaseLoggerAdapter) Trace(ctx context.Context, begin time.Time, fc f
 		chilogger.Int64("rows_affected", rows),
 		chilogger.String("sql", sql),
 	}
+	fields = append(fields, chilogger.ContextFields(ctx)...)
 
 	// 处理慢查询
 	if a.slowMonitor != nil && elapsed >= a.config.SlowQuery.Threshold {
@@ -188,6 +189,7 @@ func (a *DatabaseLoggerAdapter) trackError(ctx context.Context, err error, sql s
 		chilogger.Float64("duration_ms", float64(duration.Nanoseconds())/1e6),
 		chilogger.Err(err),
 	}
+	fields = append(fields, chilogger.ContextFields(ctx)...)
 
 	// 错误分类
 	errorCategory := a.categorizeError(err)
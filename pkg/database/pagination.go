@@ -0,0 +1,70 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PageQuery 分页查询参数
+// 由chi.PageQuery实现，定义为接口以避免依赖HTTP框架
+type PageQuery interface {
+	// Offset 查询偏移量，游标分页时为0
+	Offset() int
+	// FetchSize 查询条数
+	FetchSize() int
+	// SortField 排序字段
+	SortField() string
+	// KeyField 唯一键字段
+	KeyField() string
+	// Descending 实际查询是否为降序
+	Descending() bool
+	// Seek 游标位置，ok为false时从第一条开始查询
+	Seek() (sortValue, keyValue any, ok bool)
+}
+
+// Paginate 创建分页查询作用域
+// 偏移分页使用OFFSET/LIMIT；游标分页按(排序字段, 唯一键)进行keyset查询，
+// 字段名需为数据库列名，应通过chi.PageConfig.SortFields白名单限制
+// 参数 q: 分页参数
+// 返回值: func(*gorm.DB) *gorm.DB 用于db.Scopes的作用域函数
+func Paginate(q PageQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		sortField, keyField, desc := q.SortField(), q.KeyField(), q.Descending()
+
+		if sortValue, keyValue, ok := q.Seek(); ok {
+			db = db.Where(seekCondition(sortField, keyField, sortValue, keyValue, desc))
+		}
+
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sortField}, Desc: desc})
+		if sortField != keyField {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: keyField}, Desc: desc})
+		}
+
+		if offset := q.Offset(); offset > 0 {
+			db = db.Offset(offset)
+		}
+		return db.Limit(q.FetchSize())
+	}
+}
+
+// seekCondition 生成keyset查询条件
+// 升序：sort > v OR (sort = v AND key > k)，降序时使用小于
+func seekCondition(sortField, keyField string, sortValue, keyValue any, desc bool) clause.Expression {
+	after := func(column string, value any) clause.Expression {
+		if desc {
+			return clause.Lt{Column: clause.Column{Name: column}, Value: value}
+		}
+		return clause.Gt{Column: clause.Column{Name: column}, Value: value}
+	}
+
+	if sortField == keyField {
+		return after(keyField, keyValue)
+	}
+	return clause.Or(
+		after(sortField, sortValue),
+		clause.And(
+			clause.Eq{Column: clause.Column{Name: sortField}, Value: sortValue},
+			after(keyField, keyValue),
+		),
+	)
+}
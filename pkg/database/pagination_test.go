@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type pageQuery struct {
+	offset, size    int
+	sort, key       string
+	desc            bool
+	sortVal, keyVal any
+	seek            bool
+}
+
+func (q pageQuery) Offset() int       { return q.offset }
+func (q pageQuery) FetchSize() int    { return q.size }
+func (q pageQuery) SortField() string { return q.sort }
+func (q pageQuery) KeyField() string  { return q.key }
+func (q pageQuery) Descending() bool  { return q.desc }
+func (q pageQuery) Seek() (any, any, bool) {
+	return q.sortVal, q.keyVal, q.seek
+}
+
+func TestPaginate(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+
+	type user struct{ ID int64 }
+	toSQL := func(q PageQuery) string {
+		return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Model(&user{}).Scopes(Paginate(q)).Find(&[]user{})
+		})
+	}
+
+	sql := toSQL(pageQuery{offset: 40, size: 20, sort: "id", key: "id", desc: true})
+	assert.Equal(t, "SELECT * FROM `users` ORDER BY `id` DESC LIMIT 20 OFFSET 40", sql)
+
+	sql = toSQL(pageQuery{size: 11, sort: "score", key: "id", sortVal: 5, keyVal: 9, seek: true})
+	assert.Equal(t, "SELECT * FROM `users` WHERE (`score` > 5 OR (`score` = 5 AND `id` > 9)) ORDER BY `score`,`id` LIMIT 11", sql)
+}
@@ -0,0 +1,80 @@
+package logger
+
+import "context"
+
+// contextKey 请求上下文信息的键类型，避免与其他包的键冲突
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+	traceIDKey
+	spanIDKey
+)
+
+// WithRequestID 在上下文中设置请求ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext 获取上下文中的请求ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey).(string)
+	return v
+}
+
+// WithUserID 在上下文中设置用户ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext 获取上下文中的用户ID，不存在时返回空字符串
+func UserIDFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(userIDKey).(string)
+	return v
+}
+
+// WithTrace 在上下文中设置链路追踪ID和Span ID
+func WithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	ctx = context.WithValue(ctx, traceIDKey, traceID)
+	return context.WithValue(ctx, spanIDKey, spanID)
+}
+
+// TraceFromContext 获取上下文中的链路追踪ID和Span ID
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	traceID, _ = ctx.Value(traceIDKey).(string)
+	spanID, _ = ctx.Value(spanIDKey).(string)
+	return traceID, spanID
+}
+
+// ContextFields 将上下文中的请求信息转换为日志字段
+// 包含request_id、user_id、trace_id、span_id，值为空的字段会被忽略
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	var fields []Field
+	if v := RequestIDFromContext(ctx); v != "" {
+		fields = append(fields, String("request_id", v))
+	}
+	if v := UserIDFromContext(ctx); v != "" {
+		fields = append(fields, String("user_id", v))
+	}
+	traceID, spanID := TraceFromContext(ctx)
+	if traceID != "" {
+		fields = append(fields, String("trace_id", traceID))
+	}
+	if spanID != "" {
+		fields = append(fields, String("span_id", spanID))
+	}
+	return fields
+}
+
+// WithContext 创建附带上下文请求信息的子记录器
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// =============================================================================
+// 数据库
+// =============================================================================
+
+// databaseCollector 采集时读取数据库客户端的统计信息
+type databaseCollector struct {
+	name   string
+	source DatabaseSource
+
+	queries       *prometheus.Desc
+	queryErrors   *prometheus.Desc
+	slowQueries   *prometheus.Desc
+	openConns     *prometheus.Desc
+	inUseConns    *prometheus.Desc
+	idleConns     *prometheus.Desc
+	waitCount     *prometheus.Desc
+	waitDuration  *prometheus.Desc
+	maxOpenConns  *prometheus.Desc
+	closedMaxIdle *prometheus.Desc
+}
+
+// newDatabaseCollector 创建数据库指标采集器
+func newDatabaseCollector(namespace, name string, source DatabaseSource) *databaseCollector {
+	labels := prometheus.Labels{"name": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", metric), help, nil, labels)
+	}
+	return &databaseCollector{
+		name:          name,
+		source:        source,
+		queries:       desc("queries_total", "Total number of database queries."),
+		queryErrors:   desc("query_errors_total", "Total number of failed database queries."),
+		slowQueries:   desc("slow_queries_total", "Total number of slow database queries."),
+		openConns:     desc("open_connections", "Number of established connections, both in use and idle."),
+		inUseConns:    desc("in_use_connections", "Number of connections currently in use."),
+		idleConns:     desc("idle_connections", "Number of idle connections."),
+		waitCount:     desc("wait_count_total", "Total number of connections waited for."),
+		waitDuration:  desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection."),
+		maxOpenConns:  desc("max_open_connections", "Maximum number of open connections."),
+		closedMaxIdle: desc("closed_max_idle_total", "Total number of connections closed due to SetMaxIdleConns."),
+	}
+}
+
+// Describe 实现prometheus.Collector接口
+func (c *databaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queries
+	ch <- c.queryErrors
+	ch <- c.slowQueries
+	ch <- c.openConns
+	ch <- c.inUseConns
+	ch <- c.idleConns
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxOpenConns
+	ch <- c.closedMaxIdle
+}
+
+// Collect 实现prometheus.Collector接口
+// 未启用性能监控或慢查询监控时不输出对应指标
+func (c *databaseCollector) Collect(ch chan<- prometheus.Metric) {
+	if stats := c.source.GetPerformanceStats(); stats != nil {
+		ch <- prometheus.MustNewConstMetric(c.queries, prometheus.CounterValue, float64(stats.TotalQueries))
+		ch <- prometheus.MustNewConstMetric(c.queryErrors, prometheus.CounterValue, float64(stats.FailedQueries))
+	}
+	if stats := c.source.GetSlowQueryStats(); stats != nil {
+		ch <- prometheus.MustNewConstMetric(c.slowQueries, prometheus.CounterValue, float64(stats.SlowQueries))
+	}
+
+	db := c.source.DB()
+	if db == nil {
+		return
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	pool := sqlDB.Stats()
+	ch <- prometheus.MustNewConstMetric(c.openConns, prometheus.GaugeValue, float64(pool.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUseConns, prometheus.GaugeValue, float64(pool.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(pool.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(pool.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, pool.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxOpenConns, prometheus.GaugeValue, float64(pool.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.closedMaxIdle, prometheus.CounterValue, float64(pool.MaxIdleClosed))
+}
+
+// =============================================================================
+// Redis
+// =============================================================================
+
+// redisCollector 采集时读取Redis连接池的统计信息
+type redisCollector struct {
+	source RedisSource
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// newRedisCollector 创建Redis指标采集器
+func newRedisCollector(namespace, name string, source RedisSource) *redisCollector {
+	labels := prometheus.Labels{"name": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", metric), help, nil, labels)
+	}
+	return &redisCollector{
+		source:     source,
+		hits:       desc("hits_total", "Total number of times a free connection was found in the pool."),
+		misses:     desc("misses_total", "Total number of times a free connection was not found in the pool."),
+		timeouts:   desc("timeouts_total", "Total number of times a wait timeout occurred."),
+		totalConns: desc("connections", "Number of total connections in the pool."),
+		idleConns:  desc("idle_connections", "Number of idle connections in the pool."),
+		staleConns: desc("stale_connections_total", "Total number of stale connections removed from the pool."),
+	}
+}
+
+// Describe 实现prometheus.Collector接口
+func (c *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+// Collect 实现prometheus.Collector接口
+func (c *redisCollector) Collect(ch chan<- prometheus.Metric) {
+	client := c.source.GetClient()
+	if client == nil {
+		return
+	}
+	pool := client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(pool.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(pool.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(pool.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(pool.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(pool.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(pool.StaleConns))
+}
+
+// =============================================================================
+// 调度器
+// =============================================================================
+
+// schedulerCollector 采集时读取调度器和任务的统计信息
+type schedulerCollector struct {
+	source SchedulerSource
+
+	tasks       *prometheus.Desc
+	runningTask *prometheus.Desc
+	taskRuns    *prometheus.Desc
+	taskFails   *prometheus.Desc
+	taskLast    *prometheus.Desc
+}
+
+// newSchedulerCollector 创建调度器指标采集器
+func newSchedulerCollector(namespace, name string, source SchedulerSource) *schedulerCollector {
+	labels := prometheus.Labels{"name": name}
+	desc := func(metric, help string, variableLabels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "scheduler", metric), help, variableLabels, labels)
+	}
+	return &schedulerCollector{
+		source:      source,
+		tasks:       desc("tasks", "Number of registered tasks."),
+		runningTask: desc("running_tasks", "Number of running tasks."),
+		taskRuns:    desc("task_runs_total", "Total number of task runs.", "task_id", "task"),
+		taskFails:   desc("task_failures_total", "Total number of failed task runs.", "task_id", "task"),
+		taskLast:    desc("task_last_duration_seconds", "Duration of the last task run.", "task_id", "task"),
+	}
+}
+
+// Describe 实现prometheus.Collector接口
+func (c *schedulerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.tasks
+	ch <- c.runningTask
+	ch <- c.taskRuns
+	ch <- c.taskFails
+	ch <- c.taskLast
+}
+
+// Collect 实现prometheus.Collector接口
+func (c *schedulerCollector) Collect(ch chan<- prometheus.Metric) {
+	if stats := c.source.GetSchedulerStats(); stats != nil {
+		ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.GaugeValue, float64(stats.TotalTasks))
+		ch <- prometheus.MustNewConstMetric(c.runningTask, prometheus.GaugeValue, float64(stats.RunningTasks))
+	}
+	for _, task := range c.source.ListTasks() {
+		name := ""
+		if task.Config != nil {
+			name = task.Config.Name
+		}
+		stats := task.GetStats()
+		ch <- prometheus.MustNewConstMetric(c.taskRuns, prometheus.CounterValue, float64(stats.TotalRuns), task.ID, name)
+		ch <- prometheus.MustNewConstMetric(c.taskFails, prometheus.CounterValue, float64(stats.FailedRuns), task.ID, name)
+		ch <- prometheus.MustNewConstMetric(c.taskLast, prometheus.GaugeValue, stats.LastRunDuration.Seconds(), task.ID, name)
+	}
+}
@@ -0,0 +1,248 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"chi"
+	"chi/pkg/database"
+	"chi/pkg/scheduler"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/event"
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// unmatchedRoute 未匹配到路由的请求使用的路由标签，避免按原始路径产生无限多的标签值
+const unmatchedRoute = "unmatched"
+
+// Config 指标配置
+type Config struct {
+	// Namespace 指标名称前缀
+	Namespace string `json:"namespace" yaml:"namespace" mapstructure:"namespace"`
+	// Path 指标端点路径
+	Path string `json:"path" yaml:"path" mapstructure:"path"`
+	// Buckets 请求耗时和MongoDB命令耗时直方图的桶（秒）
+	Buckets []float64 `json:"buckets" yaml:"buckets" mapstructure:"buckets"`
+	// Registry 指标注册表，为nil时创建新的注册表并注册Go运行时和进程指标
+	Registry *prometheus.Registry `json:"-" yaml:"-" mapstructure:"-"`
+}
+
+// DefaultConfig 返回默认指标配置
+func DefaultConfig() *Config {
+	return &Config{
+		Namespace: "chi",
+		Path:      "/metrics",
+		Buckets:   prometheus.DefBuckets,
+	}
+}
+
+// DatabaseSource 数据库指标来源，pkg/database.Client实现了该接口
+type DatabaseSource interface {
+	DB() *gorm.DB
+	GetPerformanceStats() *database.PerformanceStats
+	GetSlowQueryStats() *database.SlowQueryStats
+}
+
+// RedisSource Redis指标来源，pkg/cache.Client实现了该接口
+type RedisSource interface {
+	GetClient() *redis.Client
+}
+
+// SchedulerSource 调度器指标来源，pkg/scheduler.Scheduler实现了该接口
+type SchedulerSource interface {
+	GetSchedulerStats() *scheduler.SchedulerStats
+	ListTasks() []*scheduler.Task
+}
+
+// Metrics Prometheus指标
+// 采集HTTP请求、panic、数据库、Redis、MongoDB和调度器指标，以Prometheus文本格式输出
+type Metrics struct {
+	config   *Config
+	registry *prometheus.Registry
+
+	requests  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	inFlight  prometheus.Gauge
+	panics    *prometheus.CounterVec
+	mongoCmds *prometheus.HistogramVec
+}
+
+// =============================================================================
+// 构造函数
+// =============================================================================
+
+// New 创建指标
+// 参数 config: 指标配置，为nil时使用默认配置
+// 返回值: *Metrics 指标实例
+func New(config *Config) *Metrics {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.Path == "" {
+		config.Path = "/metrics"
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = prometheus.DefBuckets
+	}
+
+	registry := config.Registry
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+		registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+	}
+
+	m := &Metrics{
+		config:   config,
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route template.",
+			Buckets:   config.Buckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: config.Namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests currently being served.",
+		}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.Namespace,
+			Name:      "panics_total",
+			Help:      "Total number of recovered panics by route template.",
+		}, []string{"route"}),
+		mongoCmds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.Namespace,
+			Name:      "mongo_command_duration_seconds",
+			Help:      "MongoDB command latency by client, command and status.",
+			Buckets:   config.Buckets,
+		}, []string{"name", "command", "status"}),
+	}
+	registry.MustRegister(m.requests, m.duration, m.inFlight, m.panics, m.mongoCmds)
+	return m
+}
+
+// Registry 获取指标注册表，可用于注册自定义指标
+// 返回值: *prometheus.Registry 指标注册表
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// =============================================================================
+// HTTP
+// =============================================================================
+
+// Middleware 创建HTTP请求指标中间件
+// 按路由模板（如/users/:id）而不是原始路径统计请求数和耗时
+// 返回值: chi.MiddlewareFunc 中间件函数
+func (m *Metrics) Middleware() chi.MiddlewareFunc {
+	return func(c *chi.Context) {
+		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		c.Next()
+
+		route := routeOf(c)
+		method := c.Request().Method
+		m.requests.WithLabelValues(method, route, strconv.Itoa(c.Writer().Status())).Inc()
+		m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RecordPanic 记录panic，实现了middlewares.PanicRecorder接口
+// 参数 c: 发生panic的请求上下文
+// 参数 err: panic的值
+func (m *Metrics) RecordPanic(c *chi.Context, err interface{}) {
+	m.panics.WithLabelValues(routeOf(c)).Inc()
+}
+
+// routeOf 获取请求的路由模板
+func routeOf(c *chi.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
+
+// Handler 获取Prometheus文本格式的指标处理器
+// 返回值: http.Handler 指标处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Mount 在服务器上注册指标中间件和指标端点
+// 中间件通过Server.Use注册，gin只对之后注册的路由生效，必须在注册业务路由之前调用，
+// 否则之前注册的路由不会被统计
+// 参数 s: 服务器
+func (m *Metrics) Mount(s *chi.Server) {
+	s.Use(m.Middleware())
+	handler := m.Handler()
+	s.GET(m.config.Path, func(c *chi.Context) {
+		handler.ServeHTTP(c.Writer(), c.Request())
+	})
+}
+
+// =============================================================================
+// MongoDB
+// =============================================================================
+
+// MongoMonitor 创建记录MongoDB命令耗时的命令监控器
+// 添加到pkg/mongo.Config.Monitors即可采集该客户端的命令耗时
+// 参数 name: 客户端名称，作为name标签
+// 返回值: *event.CommandMonitor 命令监控器
+func (m *Metrics) MongoMonitor(name string) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.mongoCmds.WithLabelValues(name, evt.CommandName, "success").Observe(evt.Duration.Seconds())
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.mongoCmds.WithLabelValues(name, evt.CommandName, "error").Observe(evt.Duration.Seconds())
+		},
+	}
+}
+
+// =============================================================================
+// 数据库、Redis和调度器
+// =============================================================================
+
+// RegisterDatabase 注册数据库指标，包括查询数、慢查询数和连接池状态
+// 参数 name: 数据库名称，作为name标签
+// 参数 source: 数据库客户端
+// 返回值: error 同名数据库已注册时的错误信息
+func (m *Metrics) RegisterDatabase(name string, source DatabaseSource) error {
+	return m.registry.Register(newDatabaseCollector(m.config.Namespace, name, source))
+}
+
+// RegisterRedis 注册Redis连接池指标
+// 参数 name: Redis客户端名称，作为name标签
+// 参数 source: Redis客户端
+// 返回值: error 同名客户端已注册时的错误信息
+func (m *Metrics) RegisterRedis(name string, source RedisSource) error {
+	return m.registry.Register(newRedisCollector(m.config.Namespace, name, source))
+}
+
+// RegisterScheduler 注册调度器指标，包括各任务的运行次数和失败次数
+// 参数 name: 调度器名称，作为name标签
+// 参数 source: 调度器
+// 返回值: error 同名调度器已注册时的错误信息
+func (m *Metrics) RegisterScheduler(name string, source SchedulerSource) error {
+	return m.registry.Register(newSchedulerCollector(m.config.Namespace, name, source))
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"chi"
+	"chi/middlewares"
+	"chi/pkg/cache"
+	"chi/pkg/database"
+	"chi/pkg/scheduler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"gorm.io/gorm"
+)
+
+// pkg客户端可以直接注册
+var (
+	_ DatabaseSource            = (*database.Client)(nil)
+	_ RedisSource               = (*cache.Client)(nil)
+	_ SchedulerSource           = (scheduler.Scheduler)(nil)
+	_ middlewares.PanicRecorder = (*Metrics)(nil)
+)
+
+// fakeDatabase 用于测试的DatabaseSource
+type fakeDatabase struct{}
+
+func (fakeDatabase) DB() *gorm.DB { return nil }
+
+func (fakeDatabase) GetPerformanceStats() *database.PerformanceStats {
+	return &database.PerformanceStats{TotalQueries: 42, FailedQueries: 2}
+}
+
+func (fakeDatabase) GetSlowQueryStats() *database.SlowQueryStats {
+	return &database.SlowQueryStats{SlowQueries: 3}
+}
+
+// fakeScheduler 用于测试的SchedulerSource
+type fakeScheduler struct {
+	tasks []*scheduler.Task
+}
+
+func (f fakeScheduler) GetSchedulerStats() *scheduler.SchedulerStats {
+	return &scheduler.SchedulerStats{TotalTasks: len(f.tasks)}
+}
+
+func (f fakeScheduler) ListTasks() []*scheduler.Task { return f.tasks }
+
+func scrape(t *testing.T, s *chi.Server) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := chi.New()
+	m := New(nil)
+	s.Use(middlewares.RecoveryWithMetrics(m))
+	m.Mount(s)
+	s.GET("/users/:id", func(c *chi.Context) { c.String(http.StatusOK, "ok") })
+	s.GET("/panic", func(c *chi.Context) { panic("boom") })
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing", "/panic"} {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, s)
+	assert.Contains(t, body, `chi_http_requests_total{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, body, `chi_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `chi_http_request_duration_seconds_count{method="GET",route="/users/:id"} 2`)
+	assert.Contains(t, body, `chi_panics_total{route="/panic"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestSourceMetrics(t *testing.T) {
+	m := New(&Config{Namespace: "app"})
+
+	require.NoError(t, m.RegisterDatabase("main", fakeDatabase{}))
+	assert.Error(t, m.RegisterDatabase("main", fakeDatabase{}))
+	require.NoError(t, m.RegisterRedis("cache", cache.NewClient(cache.DefaultConfig())))
+
+	task := scheduler.NewTask("cleanup", "清理任务", func() (interface{}, error) { return nil, nil })
+	require.NoError(t, m.RegisterScheduler("jobs", fakeScheduler{tasks: []*scheduler.Task{task}}))
+
+	monitor := m.MongoMonitor("mongo")
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", Duration: 5 * time.Millisecond},
+	})
+	monitor.Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", Duration: time.Millisecond},
+		Failure:              "duplicate key",
+	})
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Contains(t, body, `app_db_queries_total{name="main"} 42`)
+	assert.Contains(t, body, `app_db_query_errors_total{name="main"} 2`)
+	assert.Contains(t, body, `app_db_slow_queries_total{name="main"} 3`)
+	assert.Contains(t, body, `app_redis_pool_connections{name="cache"} 0`)
+	assert.Contains(t, body, `app_scheduler_tasks{name="jobs"} 1`)
+	assert.Contains(t, body, `app_scheduler_task_runs_total{name="jobs",task="清理任务",task_id="cleanup"} 0`)
+	assert.Contains(t, body, `app_scheduler_task_failures_total{name="jobs",task="清理任务",task_id="cleanup"} 0`)
+	assert.Contains(t, body, `app_mongo_command_duration_seconds_count{command="find",name="mongo",status="success"} 1`)
+	assert.Contains(t, body, `app_mongo_command_duration_seconds_count{command="insert",name="mongo",status="error"} 1`)
+}
@@ -1,8 +1,10 @@
 package mongo
 
 import (
+	"context"
 	"time"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"go.mongodb.org/mongo-driver/mongo/writeconcern"
@@ -28,6 +30,8 @@ type Config struct {
 	Auth AuthConfig `json:"auth" yaml:"auth" mapstructure:"auth"`
 	// TLS配置
 	TLS TLSConfig `json:"tls" yaml:"tls" mapstructure:"tls"`
+	// 命令监控器，用于指标采集和链路追踪，多个监控器按顺序调用
+	Monitors []*event.CommandMonitor `json:"-" yaml:"-" mapstructure:"-"`
 }
 
 // PoolConfig 连接池配置
@@ -201,9 +205,55 @@ func (c *Config) ToClientOptions() *options.ClientOptions {
 		opts.SetAuth(credential)
 	}
 
+	// 设置命令监控
+	if monitor := CombineMonitors(c.Monitors...); monitor != nil {
+		opts.SetMonitor(monitor)
+	}
+
 	return opts
 }
 
+// CombineMonitors 将多个命令监控器合并为一个
+// 参数 monitors: 命令监控器，nil会被忽略
+// 返回值: *event.CommandMonitor 合并后的监控器，没有监控器时为nil
+func CombineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	var list []*event.CommandMonitor
+	for _, m := range monitors {
+		if m != nil {
+			list = append(list, m)
+		}
+	}
+	switch len(list) {
+	case 0:
+		return nil
+	case 1:
+		return list[0]
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range list {
+				if m.Started != nil {
+					m.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range list {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range list {
+				if m.Failed != nil {
+					m.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
+
 // getReadPreference 获取读偏好
 func (c *Config) getReadPreference() *readpref.ReadPref {
 	switch c.ReadWrite.ReadPreference {
@@ -0,0 +1,92 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PageQuery 分页查询参数
+// 由chi.PageQuery实现，定义为接口以避免依赖HTTP框架
+type PageQuery interface {
+	// Offset 查询偏移量，游标分页时为0
+	Offset() int
+	// FetchSize 查询条数
+	FetchSize() int
+	// SortField 排序字段
+	SortField() string
+	// KeyField 唯一键字段
+	KeyField() string
+	// Descending 实际查询是否为降序
+	Descending() bool
+	// Seek 游标位置，ok为false时从第一条开始查询
+	Seek() (sortValue, keyValue any, ok bool)
+}
+
+// Paginate 将分页参数应用到查询条件和查询选项
+// 偏移分页使用skip/limit；游标分页按(排序字段, 唯一键)进行keyset查询。
+// 游标中的_id十六进制字符串会还原为ObjectID
+// 参数 q: 分页参数，chi.PageConfig.KeyField通常应设置为"_id"
+// 参数 filter: 原始查询条件，可以为nil
+// 返回值: bson.M 合并游标条件后的查询条件
+// 返回值: *options.FindOptions 包含排序、跳过和条数的查询选项
+func Paginate(q PageQuery, filter interface{}) (bson.M, *options.FindOptions) {
+	sortField, keyField, desc := q.SortField(), q.KeyField(), q.Descending()
+	direction := 1
+	if desc {
+		direction = -1
+	}
+
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if sortField != keyField {
+		sort = append(sort, bson.E{Key: keyField, Value: direction})
+	}
+	opts := options.Find().SetSort(sort).SetLimit(int64(q.FetchSize()))
+	if offset := q.Offset(); offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+
+	conditions := bson.A{}
+	if filter != nil {
+		conditions = append(conditions, filter)
+	}
+	if sortValue, keyValue, ok := q.Seek(); ok {
+		conditions = append(conditions, seekFilter(sortField, keyField, seekValue(sortField, sortValue), seekValue(keyField, keyValue), desc))
+	}
+
+	switch len(conditions) {
+	case 0:
+		return bson.M{}, opts
+	case 1:
+		if m, ok := conditions[0].(bson.M); ok {
+			return m, opts
+		}
+	}
+	return bson.M{"$and": conditions}, opts
+}
+
+// seekFilter 生成keyset查询条件
+// 升序：sort > v OR (sort = v AND key > k)，降序时使用$lt
+func seekFilter(sortField, keyField string, sortValue, keyValue any, desc bool) bson.M {
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+	if sortField == keyField {
+		return bson.M{keyField: bson.M{op: keyValue}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{sortField: bson.M{op: sortValue}},
+		bson.M{sortField: sortValue, keyField: bson.M{op: keyValue}},
+	}}
+}
+
+// seekValue 还原游标值，_id字段的十六进制字符串转换为ObjectID
+func seekValue(field string, value any) any {
+	if s, ok := value.(string); ok && field == "_id" {
+		if id, err := primitive.ObjectIDFromHex(s); err == nil {
+			return id
+		}
+	}
+	return value
+}
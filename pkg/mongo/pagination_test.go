@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type pageQuery struct {
+	offset, size    int
+	sort, key       string
+	desc            bool
+	sortVal, keyVal any
+	seek            bool
+}
+
+func (q pageQuery) Offset() int       { return q.offset }
+func (q pageQuery) FetchSize() int    { return q.size }
+func (q pageQuery) SortField() string { return q.sort }
+func (q pageQuery) KeyField() string  { return q.key }
+func (q pageQuery) Descending() bool  { return q.desc }
+func (q pageQuery) Seek() (any, any, bool) {
+	return q.sortVal, q.keyVal, q.seek
+}
+
+func TestPaginate(t *testing.T) {
+	filter, opts := Paginate(pageQuery{offset: 40, size: 20, sort: "_id", key: "_id", desc: true}, bson.M{"status": 1})
+	assert.Equal(t, bson.M{"status": 1}, filter)
+	assert.Equal(t, bson.D{{Key: "_id", Value: -1}}, opts.Sort)
+	assert.Equal(t, int64(20), *opts.Limit)
+	assert.Equal(t, int64(40), *opts.Skip)
+
+	id := primitive.NewObjectID()
+	filter, opts = Paginate(pageQuery{size: 11, sort: "score", key: "_id", sortVal: int64(5), keyVal: id.Hex(), seek: true}, nil)
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"score": bson.M{"$gt": int64(5)}},
+		bson.M{"score": int64(5), "_id": bson.M{"$gt": id}},
+	}}, filter)
+	assert.Equal(t, bson.D{{Key: "score", Value: 1}, {Key: "_id", Value: 1}}, opts.Sort)
+	assert.Nil(t, opts.Skip)
+}
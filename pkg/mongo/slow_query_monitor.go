@@ -7,6 +7,8 @@ import (
 	"sync"
 	"time"
 
+	chilogger "chi/pkg/logger"
+
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -352,14 +354,19 @@ func (m *SlowQueryMonitor) MonitorQuery(ctx context.Context, operation, collecti
 func extractContextInfo(ctx context.Context) map[string]interface{} {
 	info := make(map[string]interface{})
 
-	// 提取请求ID（如果存在）
-	if requestID := ctx.Value("request_id"); requestID != nil {
-		info["request_id"] = requestID
+	// 提取请求ID、用户ID和链路追踪信息（由chi.Context.Std设置）
+	for _, field := range chilogger.ContextFields(ctx) {
+		info[field.Key] = field.String
 	}
 
-	// 提取用户ID（如果存在）
-	if userID := ctx.Value("user_id"); userID != nil {
-		info["user_id"] = userID
+	// 兼容以字符串为键设置的请求ID和用户ID
+	for _, key := range []string{"request_id", "user_id"} {
+		if _, ok := info[key]; ok {
+			continue
+		}
+		if v := ctx.Value(key); v != nil {
+			info[key] = v
+		}
 	}
 
 	// 提取会话ID（如果存在）
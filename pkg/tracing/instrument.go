@@ -0,0 +1,228 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+
+	"chi/pkg/scheduler"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// GORM
+// =============================================================================
+
+// gormSpanKey 在gorm语句实例中保存span的键
+const gormSpanKey = "chi:tracing:span"
+
+// gormPlugin 为每条SQL创建span的GORM插件
+type gormPlugin struct {
+	tracer *Tracer
+}
+
+// GormPlugin 创建GORM链路追踪插件
+// 使用db.WithContext(c)传入请求上下文时，每条SQL都会作为请求span的子span：
+//
+//	client.DB().Use(tracer.GormPlugin())
+//
+// 返回值: gorm.Plugin GORM插件
+func (t *Tracer) GormPlugin() gorm.Plugin {
+	return &gormPlugin{tracer: t}
+}
+
+// Name 实现gorm.Plugin接口
+func (p *gormPlugin) Name() string {
+	return "chi:tracing"
+}
+
+// Initialize 实现gorm.Plugin接口，在增删改查、Row和Raw回调前后注册span处理
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("chi:tracing:before_create", p.before("create")),
+		callbacks.Create().After("gorm:create").Register("chi:tracing:after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("chi:tracing:before_query", p.before("query")),
+		callbacks.Query().After("gorm:query").Register("chi:tracing:after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("chi:tracing:before_update", p.before("update")),
+		callbacks.Update().After("gorm:update").Register("chi:tracing:after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("chi:tracing:before_delete", p.before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("chi:tracing:after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("chi:tracing:before_row", p.before("row")),
+		callbacks.Row().After("gorm:row").Register("chi:tracing:after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("chi:tracing:before_raw", p.before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("chi:tracing:after_raw", p.after),
+	)
+}
+
+// before 创建span并替换语句的上下文
+func (p *gormPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, span := p.tracer.startSpan(ctx, "gorm."+operation, trace.SpanKindClient,
+			attribute.String("db.system", db.Dialector.Name()),
+			attribute.String("db.operation.name", operation),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// after 记录SQL和影响行数并结束span，未找到记录不视为错误
+func (p *gormPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	span.SetAttributes(
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	endSpan(span, err)
+}
+
+// =============================================================================
+// MongoDB
+// =============================================================================
+
+// MongoMonitor 创建为每个MongoDB命令创建span的命令监控器
+// 添加到pkg/mongo.Config.Monitors即可，命令的上下文中有span时作为其子span
+// 返回值: *event.CommandMonitor 命令监控器
+func (t *Tracer) MongoMonitor() *event.CommandMonitor {
+	var spans sync.Map
+	key := func(connectionID string, requestID int64) string {
+		return connectionID + "#" + strconv.FormatInt(requestID, 10)
+	}
+	finish := func(evt event.CommandFinishedEvent, err error) {
+		if v, ok := spans.LoadAndDelete(key(evt.ConnectionID, evt.RequestID)); ok {
+			endSpan(v.(trace.Span), err)
+		}
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			_, span := t.startSpan(ctx, "mongo."+evt.CommandName, trace.SpanKindClient,
+				attribute.String("db.system", "mongodb"),
+				attribute.String("db.namespace", evt.DatabaseName),
+				attribute.String("db.operation.name", evt.CommandName),
+			)
+			spans.Store(key(evt.ConnectionID, evt.RequestID), span)
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			finish(evt.CommandFinishedEvent, nil)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			finish(evt.CommandFinishedEvent, errors.New(evt.Failure))
+		},
+	}
+}
+
+// =============================================================================
+// Redis
+// =============================================================================
+
+// redisHook 为每个Redis命令创建span的go-redis钩子
+type redisHook struct {
+	tracer *Tracer
+}
+
+// RedisHook 创建Redis链路追踪钩子
+// 命令的上下文中有span时作为其子span，只记录命令名称，不记录参数：
+//
+//	cacheClient.GetClient().AddHook(tracer.RedisHook())
+//
+// 返回值: redis.Hook go-redis钩子
+func (t *Tracer) RedisHook() redis.Hook {
+	return &redisHook{tracer: t}
+}
+
+// DialHook 实现redis.Hook接口
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.tracer.startSpan(ctx, "redis.dial", trace.SpanKindClient,
+			attribute.String("db.system", "redis"),
+			attribute.String("server.address", addr),
+		)
+		conn, err := next(ctx, network, addr)
+		endSpan(span, err)
+		return conn, err
+	}
+}
+
+// ProcessHook 实现redis.Hook接口
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.tracer.startSpan(ctx, "redis."+cmd.Name(), trace.SpanKindClient,
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation.name", cmd.Name()),
+		)
+		err := next(ctx, cmd)
+		endSpan(span, redisError(err))
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现redis.Hook接口
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := h.tracer.startSpan(ctx, "redis.pipeline", trace.SpanKindClient,
+			attribute.String("db.system", "redis"),
+			attribute.Int("db.operation.batch.size", len(cmds)),
+		)
+		err := next(ctx, cmds)
+		endSpan(span, redisError(err))
+		return err
+	}
+}
+
+// redisError 键不存在不视为错误
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// =============================================================================
+// 调度器
+// =============================================================================
+
+// WrapTask 包装任务函数，每次执行都创建一个span
+// 定时任务没有请求上下文，每次执行都是一条新的链路
+// 参数 task: 任务，在添加到调度器之前包装
+// 返回值: *scheduler.Task 包装后的任务（与参数相同）
+func (t *Tracer) WrapTask(task *scheduler.Task) *scheduler.Task {
+	fn := task.Func
+	if fn == nil {
+		return task
+	}
+	name := task.ID
+	if task.Config != nil && task.Config.Name != "" {
+		name = task.Config.Name
+	}
+	task.Func = func() (interface{}, error) {
+		_, span := t.startSpan(context.Background(), "task "+name, trace.SpanKindInternal,
+			attribute.String("task.id", task.ID),
+			attribute.String("task.name", name),
+		)
+		result, err := fn()
+		endSpan(span, err)
+		return result, err
+	}
+	return task
+}
@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"chi"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// instrumentationName 创建span使用的instrumentation名称
+const instrumentationName = "chi/pkg/tracing"
+
+// Config 链路追踪配置
+type Config struct {
+	// ServiceName 服务名称，作为service.name资源属性
+	ServiceName string `json:"service_name" yaml:"service_name" mapstructure:"service_name"`
+	// Endpoint OTLP/HTTP接收地址，如"localhost:4318"，为空时使用OTEL_EXPORTER_OTLP_ENDPOINT环境变量
+	Endpoint string `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint"`
+	// Insecure 是否使用HTTP而不是HTTPS连接OTLP接收端
+	Insecure bool `json:"insecure" yaml:"insecure" mapstructure:"insecure"`
+	// Headers 发送到OTLP接收端的额外请求头，如认证信息
+	Headers map[string]string `json:"headers" yaml:"headers" mapstructure:"headers"`
+	// SampleRatio 根span的采样比例，0到1之间，有父span时跟随父span的采样决定
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio" mapstructure:"sample_ratio"`
+	// Exporter 自定义导出器，设置后不使用OTLP，测试时可以使用tracetest.InMemoryExporter
+	Exporter sdktrace.SpanExporter `json:"-" yaml:"-" mapstructure:"-"`
+}
+
+// DefaultConfig 返回默认链路追踪配置
+func DefaultConfig() *Config {
+	return &Config{
+		ServiceName: "chi",
+		SampleRatio: 1,
+	}
+}
+
+// Tracer 链路追踪
+// 从请求头提取W3C traceparent/baggage并创建服务端span，span通过chi.Context传递，
+// 数据库、MongoDB、Redis和调度器任务在其中创建子span
+type Tracer struct {
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// =============================================================================
+// 构造函数
+// =============================================================================
+
+// New 创建链路追踪，并设置为全局TracerProvider和传播器
+// 参数 config: 链路追踪配置，为nil时使用默认配置
+// 返回值: *Tracer 链路追踪实例
+// 返回值: error 创建导出器失败时的错误信息
+func New(config *Config) (*Tracer, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	exporter := config.Exporter
+	if exporter == nil {
+		var opts []otlptracehttp.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+		}
+		var err error
+		if exporter, err = otlptracehttp.New(context.Background(), opts...); err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", config.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return &Tracer{
+		provider:   provider,
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagator,
+	}, nil
+}
+
+// Provider 获取TracerProvider，可用于创建自定义span
+// 返回值: *sdktrace.TracerProvider TracerProvider
+func (t *Tracer) Provider() *sdktrace.TracerProvider {
+	return t.provider
+}
+
+// ForceFlush 立即导出所有已结束的span
+// 参数 ctx: 上下文
+// 返回值: error 导出失败时的错误信息
+func (t *Tracer) ForceFlush(ctx context.Context) error {
+	return t.provider.ForceFlush(ctx)
+}
+
+// Shutdown 导出剩余的span并关闭导出器
+// 参数 ctx: 上下文
+// 返回值: error 关闭失败时的错误信息
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
+
+// =============================================================================
+// HTTP
+// =============================================================================
+
+// Middleware 创建链路追踪中间件
+// 从请求头提取W3C traceparent和baggage，按路由模板（如"GET /users/:id"）命名服务端span，
+// 并将span放入请求上下文，处理函数可以通过c.Std()传给下游；
+// 直接把chi.Context作为context.Context传递时需要启用gin.Engine.ContextWithFallback，Mount会自动启用
+// 返回值: chi.MiddlewareFunc 中间件函数
+func (t *Tracer) Middleware() chi.MiddlewareFunc {
+	return func(c *chi.Context) {
+		req := c.Request()
+		ctx := t.propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		route := c.FullPath()
+		name := req.Method + " " + route
+		if route == "" {
+			name = req.Method
+		}
+		ctx, span := t.tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", req.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+			),
+		)
+		defer span.End()
+		c.Context.Request = req.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer().Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if errs := c.Context.Errors; len(errs) > 0 {
+			span.SetAttributes(attribute.String("error.message", errs.String()))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// Mount 在服务器上注册链路追踪中间件
+// 同时启用gin.Engine.ContextWithFallback，使chi.Context作为context.Context使用时
+// 回退到请求上下文，从而携带span和取消信号；服务停止后应调用Shutdown导出剩余的span
+// 参数 s: 服务器
+func (t *Tracer) Mount(s *chi.Server) {
+	s.Engine().ContextWithFallback = true
+	s.Use(t.Middleware())
+}
+
+// Inject 将当前span注入到请求头，用于调用下游服务
+// 参数 ctx: 包含span的上下文，如chi.Context
+// 参数 header: 下游请求的请求头
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// startSpan 创建客户端子span
+func (t *Tracer) startSpan(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// endSpan 记录错误并结束span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
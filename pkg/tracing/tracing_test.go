@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chi"
+	"chi/pkg/logger"
+	"chi/pkg/scheduler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func newTestTracer(t *testing.T) (*Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer, err := New(&Config{ServiceName: "test", SampleRatio: 1, Exporter: exporter})
+	require.NoError(t, err)
+	t.Cleanup(func() { tracer.Shutdown(context.Background()) })
+	return tracer, exporter
+}
+
+// spansByName 导出所有span并按名称索引
+func spansByName(t *testing.T, tracer *Tracer, exporter *tracetest.InMemoryExporter) map[string]tracetest.SpanStub {
+	require.NoError(t, tracer.ForceFlush(context.Background()))
+	spans := make(map[string]tracetest.SpanStub)
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+	return spans
+}
+
+func TestTracingPropagation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer, exporter := newTestTracer(t)
+
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "root@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tracer.GormPlugin()))
+
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer rdb.Close()
+	rdb.AddHook(tracer.RedisHook())
+
+	monitor := tracer.MongoMonitor()
+
+	s := chi.New()
+	tracer.Mount(s)
+	s.GET("/users/:id", func(c *chi.Context) {
+		var users []map[string]interface{}
+		db.WithContext(c).Table("users").Where("id = ?", c.Param("id")).Find(&users)
+		rdb.Get(c, "user:"+c.Param("id"))
+
+		monitor.Started(c, &event.CommandStartedEvent{CommandName: "find", DatabaseName: "app", RequestID: 1, ConnectionID: "conn"})
+		monitor.Succeeded(c, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, ConnectionID: "conn"}})
+
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	s.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := spansByName(t, tracer, exporter)
+	server, ok := spans["GET /users/:id"]
+	require.True(t, ok)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, codes.Error, server.Status.Code)
+
+	for _, name := range []string{"gorm.query", "redis.get", "mongo.find"} {
+		child, ok := spans[name]
+		require.True(t, ok, name)
+		assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID(), name)
+		assert.Equal(t, server.SpanContext.TraceID(), child.SpanContext.TraceID(), name)
+	}
+	assert.Equal(t, codes.Error, spans["redis.get"].Status.Code)
+	assert.Equal(t, codes.Unset, spans["mongo.find"].Status.Code)
+}
+
+func TestWrapTask(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	task := tracer.WrapTask(scheduler.NewTask("sync", "同步任务", func() (interface{}, error) {
+		return nil, errors.New("upstream unavailable")
+	}))
+	_, err := task.Execute()
+	assert.Error(t, err)
+
+	spans := spansByName(t, tracer, exporter)
+	span, ok := spans["task 同步任务"]
+	require.True(t, ok)
+	assert.False(t, span.Parent.IsValid())
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, "upstream unavailable", span.Status.Description)
+}
+
+func TestTracingContextIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer, exporter := newTestTracer(t)
+
+	s := chi.New()
+	tracer.Mount(s)
+	s.SetRenderer(chi.NewRenderer(chi.RenderConfig{TraceID: true}))
+	var traceID, spanID string
+	s.GET("/root", func(c *chi.Context) {
+		traceID, spanID = logger.TraceFromContext(c.Std())
+		chi.SuccessRes(c, nil)
+	})
+
+	for _, traceparent := range []string{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"} {
+		exporter.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/root", nil)
+		if traceparent != "" {
+			req.Header.Set("traceparent", traceparent)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, req)
+
+		server, ok := spansByName(t, tracer, exporter)["GET /root"]
+		require.True(t, ok)
+		assert.Equal(t, server.SpanContext.TraceID().String(), traceID)
+		assert.Equal(t, server.SpanContext.SpanID().String(), spanID)
+		assert.Contains(t, w.Body.String(), `"trace_id":"`+traceID+`"`)
+	}
+}
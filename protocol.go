@@ -0,0 +1,72 @@
+package chi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// =============================================================================
+// HTTP/2明文（h2c）
+// =============================================================================
+
+// RunH2C 启动HTTP/2明文（h2c）服务器并支持优雅关机
+// 同时支持prior knowledge和HTTP/1.1 Upgrade两种方式，普通HTTP/1.1请求照常处理，
+// 适用于服务网格中sidecar之间的通信
+// 参数 addr: 监听地址，如":8080"
+// 参数 timeout: 关闭超时时间，默认30秒
+// 返回值: error 启动或关闭过程中的错误信息
+func (s *Server) RunH2C(addr string, timeout ...time.Duration) error {
+	server := s.trackServer(s.newHTTPServer(addr))
+	enableH2C(server)
+	return s.serveGracefully(s.serve(server.ListenAndServe), timeout...)
+}
+
+// enableH2C 为HTTP服务器启用h2c
+// h2c连接由http2.Server接管，注册到HTTP服务器后，Shutdown时会向这些连接发送GOAWAY
+func enableH2C(server *http.Server) {
+	h2s := &http2.Server{IdleTimeout: server.IdleTimeout}
+	// 未设置TLSConfig时ConfigureServer不会返回错误
+	_ = http2.ConfigureServer(server, h2s)
+	server.Handler = h2c.NewHandler(server.Handler, h2s)
+}
+
+// =============================================================================
+// HTTP/3
+// =============================================================================
+
+// serveHTTP3 在监听器的UDP连接上启动HTTP/3服务
+// HTTP/3服务与TCP服务共用处理器和TLS配置，并随Server一起优雅关闭
+// 参数 l: 已绑定UDP连接的监听器
+// 参数 handler: 处理请求的Handler
+// 参数 errCh: 服务退出时接收其错误
+// 返回值: http.Handler 在TCP响应中添加Alt-Svc响应头的处理器
+func (s *Server) serveHTTP3(l *listener, handler http.Handler, errCh chan<- error) http.Handler {
+	cfg := s.cfg.Server
+	server := &http3.Server{
+		Handler:        handler,
+		TLSConfig:      l.tlsConfig,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
+		IdleTimeout:    cfg.Timeout.Idle,
+	}
+	s.track(server)
+
+	go func() {
+		err := serveError(server.Serve(l.packetConn))
+		l.packetConn.Close()
+		if err != nil {
+			err = fmt.Errorf("%s (http3): %w", l.name, err)
+		}
+		errCh <- err
+	}()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// 服务尚未开始监听时无法生成Alt-Svc，忽略错误
+		_ = server.SetQUICHeaders(w.Header())
+		handler.ServeHTTP(w, req)
+	})
+}
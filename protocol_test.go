@@ -0,0 +1,113 @@
+package chi
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func TestH2C(t *testing.T) {
+	s := newTestServer()
+	s.GET("/proto", func(c *Context) { c.String(http.StatusOK, c.Context.Request.Proto) })
+	require.NoError(t, s.Listen("127.0.0.1:0", WithH2C()))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+	addr := s.Addrs()[0].String()
+
+	// prior knowledge
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get("http://" + addr + "/proto")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/2.0", string(body))
+
+	// HTTP/1.1 Upgrade
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /proto HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade, HTTP2-Settings\r\n"+
+		"Upgrade: h2c\r\nHTTP2-Settings: AAMAAABkAARAAAAAAAIAAAAA\r\n\r\n")
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(status, "HTTP/1.1 101"), status)
+
+	// 普通HTTP/1.1请求
+	resp, err = http.Get("http://" + addr + "/proto")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", string(body))
+
+	s.quit <- syscall.SIGTERM
+	require.NoError(t, <-errCh)
+}
+
+func TestHTTP3(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, t.TempDir(), "localhost", 1, x509.ExtKeyUsageServerAuth, "localhost")
+
+	s := newTestServer()
+	s.GET("/proto", func(c *Context) { c.String(http.StatusOK, c.Context.Request.Proto) })
+	require.NoError(t, s.Listen("127.0.0.1:0", WithTLS(certFile, keyFile), WithHTTP3()))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+	_, port, _ := net.SplitHostPort(s.Addrs()[0].String())
+	url := "https://localhost:" + port + "/proto"
+	tlsConfig := &tls.Config{RootCAs: ca.pool}
+
+	// TCP响应通告HTTP/3
+	resp, err := (&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}).Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, fmt.Sprintf(`h3=":%s"; ma=2592000`, port), resp.Header.Get("Alt-Svc"))
+
+	transport := &http3.Transport{TLSClientConfig: tlsConfig}
+	defer transport.Close()
+	resp, err = (&http.Client{Transport: transport}).Get(url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/3.0", string(body))
+
+	s.quit <- syscall.SIGTERM
+	require.NoError(t, <-errCh)
+}
+
+func TestProtocolConfig(t *testing.T) {
+	s := newTestServer()
+	require.NoError(t, s.Listen("127.0.0.1:0", WithHTTP3()))
+	_, err := s.startListeners()
+	assert.ErrorContains(t, err, "http3 requires tls")
+
+	cfg := DefaultConfig()
+	cfg.Server.HTTP3 = true
+	assert.Error(t, cfg.Validate())
+	cfg.Server.HTTP3 = false
+	cfg.Server.H2C = true
+	cfg.Server.TLS = TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}
+	assert.Error(t, cfg.Validate())
+}
@@ -0,0 +1,203 @@
+package chi
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// ResponseRenderer 响应渲染器
+// Res、SuccessRes、FailRes通过渲染器输出响应，可替换响应包装结构和输出格式
+type ResponseRenderer interface {
+	// Render 渲染响应
+	// 参数 ctx: 请求上下文
+	// 参数 status: HTTP状态码
+	// 参数 resp: 统一响应结构
+	Render(ctx *Context, status int, resp *Response)
+}
+
+// RendererFunc 函数形式的响应渲染器
+type RendererFunc func(ctx *Context, status int, resp *Response)
+
+// Render 实现ResponseRenderer接口
+func (f RendererFunc) Render(ctx *Context, status int, resp *Response) {
+	f(ctx, status, resp)
+}
+
+// RenderConfig 默认渲染器配置
+type RenderConfig struct {
+	// Formats 支持的响应格式（MIME类型），按Accept头协商，第一个为默认格式
+	// 支持JSON、XML、YAML、TOML、MessagePack和Protobuf，Protobuf仅在data实现proto.Message时生效
+	Formats []string
+	// RequestID 是否在响应中注入请求ID
+	RequestID bool
+	// TraceID 是否在响应中注入链路追踪ID
+	TraceID bool
+	// Timestamp 是否在响应中注入Unix毫秒时间戳
+	Timestamp bool
+	// Envelope 自定义响应包装结构，为nil时使用Response
+	Envelope func(ctx *Context, resp *Response) any
+}
+
+// DefaultRenderConfig 默认渲染器配置，仅输出JSON
+var DefaultRenderConfig = RenderConfig{
+	Formats: []string{binding.MIMEJSON},
+}
+
+// defaultRenderer 未设置渲染器时使用的渲染器
+var defaultRenderer = NewRenderer(DefaultRenderConfig)
+
+// rendererContextKey 在gin.Context中保存路由组渲染器的键
+const rendererContextKey = "chi.renderer"
+
+// requestIDHeader 请求ID请求头
+const requestIDHeader = "X-Request-ID"
+
+// responseFormats 各格式的输出函数
+var responseFormats = map[string]func(c *gin.Context, status int, obj any){
+	binding.MIMEJSON:  (*gin.Context).JSON,
+	binding.MIMEXML:   writeXML,
+	binding.MIMEXML2:  writeXML,
+	binding.MIMEYAML:  (*gin.Context).YAML,
+	binding.MIMEYAML2: (*gin.Context).YAML,
+	binding.MIMETOML:  (*gin.Context).TOML,
+}
+
+// =============================================================================
+// 默认渲染器
+// =============================================================================
+
+// renderer 基于RenderConfig的渲染器
+type renderer struct {
+	config RenderConfig
+}
+
+// NewRenderer 创建响应渲染器
+// 参数 config: 渲染器配置，Formats为空时仅输出JSON
+// 返回值: ResponseRenderer 渲染器实例
+func NewRenderer(config RenderConfig) ResponseRenderer {
+	if len(config.Formats) == 0 {
+		config.Formats = DefaultRenderConfig.Formats
+	}
+	return &renderer{config: config}
+}
+
+// Render 实现ResponseRenderer接口
+// 注入请求信息后按Accept头协商输出格式，无法协商时使用第一个格式
+func (r *renderer) Render(ctx *Context, status int, resp *Response) {
+	if r.config.RequestID {
+		resp.RequestID = requestID(ctx)
+	}
+	if r.config.TraceID {
+		resp.TraceID, _ = traceParent(ctx)
+	}
+	if r.config.Timestamp {
+		resp.Timestamp = time.Now().UnixMilli()
+	}
+
+	format := r.config.Formats[0]
+	if len(r.config.Formats) > 1 {
+		if negotiated := ctx.NegotiateFormat(r.config.Formats...); negotiated != "" {
+			format = negotiated
+		}
+	}
+
+	if format == binding.MIMEPROTOBUF {
+		if msg, ok := resp.Data.(proto.Message); ok {
+			ctx.ProtoBuf(status, msg)
+			return
+		}
+		format = binding.MIMEJSON
+	}
+
+	var obj any = resp
+	if r.config.Envelope != nil {
+		obj = r.config.Envelope(ctx, resp)
+	}
+
+	write, ok := responseFormats[format]
+	if !ok {
+		write = (*gin.Context).JSON
+	}
+	write(ctx.Context, status, obj)
+}
+
+// writeXML 输出XML响应
+// encoding/xml无法编码map（如Error.Details），无法编码时回退到JSON，避免输出被截断的响应体
+func writeXML(c *gin.Context, status int, obj any) {
+	body, err := xml.Marshal(obj)
+	if err != nil {
+		c.JSON(status, obj)
+		return
+	}
+	c.Data(status, "application/xml; charset=utf-8", body)
+}
+
+// =============================================================================
+// 渲染器设置
+// =============================================================================
+
+// SetRenderer 设置服务器的响应渲染器
+// 参数 r: 响应渲染器，为nil时恢复默认渲染器
+func (s *Server) SetRenderer(r ResponseRenderer) {
+	s.renderer = r
+}
+
+// SetRenderer 设置路由组的响应渲染器
+// 优先于服务器的渲染器，仅对调用之后在该组及子组注册的路由生效
+// 参数 r: 响应渲染器
+func (rg *RouterGroup) SetRenderer(r ResponseRenderer) {
+	rg.group.Use(func(c *gin.Context) {
+		c.Set(rendererContextKey, r)
+	})
+}
+
+// renderResponse 使用当前请求的渲染器输出响应
+// 优先级：路由组渲染器 > 服务器渲染器 > 默认渲染器
+func renderResponse(ctx *Context, status int, resp *Response) {
+	if v, ok := ctx.Context.Get(rendererContextKey); ok {
+		if r, ok := v.(ResponseRenderer); ok {
+			r.Render(ctx, status, resp)
+			return
+		}
+	}
+	if s := ctx.server(); s != nil && s.renderer != nil {
+		s.renderer.Render(ctx, status, resp)
+		return
+	}
+	defaultRenderer.Render(ctx, status, resp)
+}
+
+// requestID 获取请求ID，依次查找RequestIDKey、响应头和请求头
+func requestID(ctx *Context) string {
+	if id, _ := GetValue(ctx, RequestIDKey); id != "" {
+		return id
+	}
+	if id := ctx.Context.Writer.Header().Get(requestIDHeader); id != "" {
+		return id
+	}
+	return ctx.GetHeader(requestIDHeader)
+}
+
+// traceParent 获取当前请求的链路追踪ID和Span ID
+// 优先使用请求上下文中的span（如pkg/tracing创建的服务端span），
+// 未启用链路追踪时从W3C traceparent请求头中解析，此时Span ID为上游调用方的span
+func traceParent(ctx *Context) (traceID, spanID string) {
+	if sc := trace.SpanContextFromContext(ctx.Context.Request.Context()); sc.IsValid() {
+		return sc.TraceID().String(), sc.SpanID().String()
+	}
+	parts := strings.Split(ctx.GetHeader("traceparent"), "-")
+	if len(parts) == 4 && len(parts[1]) == 32 && len(parts[2]) == 16 {
+		return parts[1], parts[2]
+	}
+	return "", ""
+}
@@ -0,0 +1,20 @@
+//go:build !nomsgpack
+
+package chi
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+// 与gin保持一致，使用nomsgpack构建标签时不支持MessagePack响应
+func init() {
+	responseFormats[binding.MIMEMSGPACK] = writeMsgPack
+	responseFormats[binding.MIMEMSGPACK2] = writeMsgPack
+}
+
+// writeMsgPack 输出MessagePack响应
+func writeMsgPack(c *gin.Context, status int, obj any) {
+	c.Render(status, render.MsgPack{Data: obj})
+}
@@ -0,0 +1,98 @@
+package chi
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer(t *testing.T) {
+	t.Run("Negotiation", func(t *testing.T) {
+		s := newTestServer()
+		s.SetRenderer(NewRenderer(RenderConfig{
+			Formats: []string{binding.MIMEJSON, binding.MIMEXML, binding.MIMEMSGPACK},
+		}))
+		s.GET("/user", func(c *Context) { SuccessRes(c, "tom") })
+
+		w := doRequest(s, http.MethodGet, "/user", "", nil)
+		assert.Contains(t, w.Header().Get("Content-Type"), binding.MIMEJSON)
+		assert.Equal(t, "tom", decodeResponse(t, w)["data"])
+
+		w = doRequest(s, http.MethodGet, "/user", "", map[string]string{"Accept": "application/xml"})
+		assert.Contains(t, w.Header().Get("Content-Type"), binding.MIMEXML)
+		var resp struct {
+			Code int    `xml:"code"`
+			Data string `xml:"data"`
+		}
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 200, resp.Code)
+		assert.Equal(t, "tom", resp.Data)
+
+		w = doRequest(s, http.MethodGet, "/user", "", map[string]string{"Accept": binding.MIMEMSGPACK})
+		assert.Contains(t, w.Header().Get("Content-Type"), binding.MIMEMSGPACK2)
+	})
+
+	t.Run("XMLFallback", func(t *testing.T) {
+		s := newTestServer()
+		s.SetRenderer(NewRenderer(RenderConfig{Formats: []string{binding.MIMEJSON, binding.MIMEXML}}))
+		s.GET("/user", func(c *Context) { Res(c, ErrNotFound.WithDetails(map[string]any{"id": "42"})) })
+
+		// map无法编码为XML时回退到完整的JSON响应
+		w := doRequest(s, http.MethodGet, "/user", "", map[string]string{"Accept": "application/xml"})
+		assert.Contains(t, w.Header().Get("Content-Type"), binding.MIMEJSON)
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(ErrNotFound.Code), body["code"])
+		assert.Equal(t, map[string]any{"id": "42"}, body["data"])
+	})
+
+	t.Run("InjectFields", func(t *testing.T) {
+		s := newTestServer()
+		s.SetRenderer(NewRenderer(RenderConfig{RequestID: true, TraceID: true, Timestamp: true}))
+		s.GET("/user", func(c *Context) { Res(c, ErrNotFound) })
+
+		w := doRequest(s, http.MethodGet, "/user", "", map[string]string{
+			"X-Request-ID": "req-1",
+			"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		})
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(ErrNotFound.Code), body["code"])
+		assert.Equal(t, "req-1", body["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", body["trace_id"])
+		assert.NotZero(t, body["timestamp"])
+	})
+
+	t.Run("GroupEnvelope", func(t *testing.T) {
+		s := newTestServer()
+		s.GET("/default", func(c *Context) { SuccessRes(c, 1) })
+
+		partner := s.Group("/partner")
+		partner.SetRenderer(NewRenderer(RenderConfig{
+			Envelope: func(ctx *Context, resp *Response) any {
+				return map[string]any{"errcode": resp.Code, "errmsg": resp.Message, "result": resp.Data}
+			},
+		}))
+		partner.GET("/user", func(c *Context) { SuccessRes(c, "tom") })
+
+		body := decodeResponse(t, doRequest(s, http.MethodGet, "/partner/user", "", nil))
+		assert.Equal(t, map[string]interface{}{"errcode": float64(200), "errmsg": "success", "result": "tom"}, body)
+
+		body = decodeResponse(t, doRequest(s, http.MethodGet, "/default", "", nil))
+		assert.Equal(t, float64(200), body["code"])
+		assert.NotContains(t, body, "request_id")
+	})
+
+	t.Run("RendererFunc", func(t *testing.T) {
+		s := newTestServer()
+		s.SetRenderer(RendererFunc(func(ctx *Context, status int, resp *Response) {
+			ctx.String(status, resp.Message)
+		}))
+		s.GET("/user", func(c *Context) { FailRes(c, ErrForbidden) })
+
+		w := doRequest(s, http.MethodGet, "/user", "", nil)
+		assert.Equal(t, ErrForbidden.Message, w.Body.String())
+	})
+}
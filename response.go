@@ -1,6 +1,7 @@
 package chi
 
 import (
+	"encoding/xml"
 	"errors"
 	"net/http"
 )
@@ -8,9 +9,13 @@ import (
 // Response
 // 响应Model
 type Response struct {
-	Code    int    `json:"code"`
-	Data    any    `json:"data"`
-	Message string `json:"message"`
+	XMLName   xml.Name `json:"-" yaml:"-" toml:"-" xml:"response"`
+	Code      int      `json:"code" yaml:"code" toml:"code" xml:"code"`
+	Data      any      `json:"data" yaml:"data" toml:"data" xml:"data"`
+	Message   string   `json:"message" yaml:"message" toml:"message" xml:"message"`
+	RequestID string   `json:"request_id,omitempty" yaml:"request_id,omitempty" toml:"request_id,omitempty" xml:"request_id,omitempty"`
+	TraceID   string   `json:"trace_id,omitempty" yaml:"trace_id,omitempty" toml:"trace_id,omitempty" xml:"trace_id,omitempty"`
+	Timestamp int64    `json:"timestamp,omitempty" yaml:"timestamp,omitempty" toml:"timestamp,omitempty" xml:"timestamp,omitempty"`
 }
 
 // NewResponse
@@ -53,29 +58,44 @@ func NewPageResp[T any](total int64, list T) *PageResp[T] {
 
 // Res
 // Api响应
+// 响应通过当前请求的ResponseRenderer输出，见SetRenderer
+// 错误会记录到上下文的错误列表中；默认始终返回HTTP 200，
+// Server启用UseHTTPStatus时使用错误对应的HTTP状态码
 func Res(ctx *Context, err error, data ...any) {
 	if err != nil {
+		ctx.Context.Error(err)
+
+		// 验证错误返回字段级错误详情
+		if fields := TranslateValidation(ctx, err); fields != nil {
+			renderResponse(ctx, responseStatus(ctx, ErrBinding.Status), NewResponse(ErrBinding.Code, fields, ErrBinding.Message))
+			return
+		}
+
 		var e *Error
 		if !errors.As(err, &e) {
-			ctx.JSON(http.StatusOK, NewErrResponse(500, "未知异常"))
+			renderResponse(ctx, responseStatus(ctx, http.StatusInternalServerError), NewErrResponse(500, "未知异常"))
 			return
 		}
-		ctx.JSON(http.StatusOK, NewErrResponse(e.Code, e.Message))
+		var details any
+		if len(e.Details) > 0 {
+			details = e.Details
+		}
+		renderResponse(ctx, responseStatus(ctx, e.Status), NewResponse(e.Code, details, e.Message))
 		return
 	}
 
 	if len(data) == 0 {
-		ctx.JSON(http.StatusOK, NewOkResponse(nil))
+		renderResponse(ctx, http.StatusOK, NewOkResponse(nil))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, NewOkResponse(data[0]))
+	renderResponse(ctx, http.StatusOK, NewOkResponse(data[0]))
 }
 
 // SuccessRes
 // 成功响应
 func SuccessRes(ctx *Context, data any) {
-	ctx.JSON(http.StatusOK, NewOkResponse(data))
+	renderResponse(ctx, http.StatusOK, NewOkResponse(data))
 }
 
 // FailRes
@@ -83,3 +103,11 @@ func SuccessRes(ctx *Context, data any) {
 func FailRes(ctx *Context, err error) {
 	Res(ctx, err)
 }
+
+// responseStatus 获取错误响应的HTTP状态码
+func responseStatus(ctx *Context, status int) int {
+	if s := ctx.server(); s != nil && s.useHTTPStatus && status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,368 @@
+package chi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// 平滑重启时传递给新进程的环境变量
+const (
+	// envListenFds 继承的监听器地址，每个地址经过URL编码后以逗号分隔，依次对应从3开始的文件描述符
+	envListenFds = "CHI_LISTEN_FDS"
+	// envReadyFd 新进程就绪后写入的管道文件描述符
+	envReadyFd = "CHI_READY_FD"
+)
+
+// udpSuffix HTTP/3 UDP连接在继承列表中的地址后缀
+const udpSuffix = "#udp"
+
+// RestartConfig 平滑重启配置
+type RestartConfig struct {
+	// Signals 触发平滑重启的系统信号
+	Signals []os.Signal
+	// ReadyTimeout 等待新进程就绪的超时时间，超时后终止新进程并继续使用当前进程
+	ReadyTimeout time.Duration
+	// Path 新进程的可执行文件路径，为空时使用当前可执行文件（部署时替换的新版本）
+	Path string
+	// Args 新进程的命令行参数，为nil时使用当前进程的参数
+	Args []string
+	// OnError 重启失败时的回调，为nil时输出到gin.DefaultErrorWriter
+	OnError func(err error)
+}
+
+// DefaultRestartConfig 默认平滑重启配置
+var DefaultRestartConfig = RestartConfig{
+	Signals:      restartSignals,
+	ReadyTimeout: 30 * time.Second,
+}
+
+// filer 可以获取底层文件描述符的监听器或连接
+type filer interface {
+	File() (*os.File, error)
+}
+
+// inherited 从父进程继承的文件描述符，按地址索引
+var inherited struct {
+	once  sync.Once
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+// =============================================================================
+// 平滑重启
+// =============================================================================
+
+// EnableGracefulRestart 启用平滑重启
+// 收到重启信号（默认SIGHUP、SIGUSR2）时启动新进程并通过文件描述符传递所有监听器，
+// 新进程就绪后当前进程优雅关闭并退出Serve、Start或App.Run，连接不会中断。
+// 只有通过Serve、Start和App.Run启动的监听器会被传递
+// 参数 config: 可选的平滑重启配置，默认使用DefaultRestartConfig
+func (s *Server) EnableGracefulRestart(config ...RestartConfig) {
+	cfg := DefaultRestartConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = DefaultRestartConfig.Signals
+	}
+	if cfg.ReadyTimeout <= 0 {
+		cfg.ReadyTimeout = DefaultRestartConfig.ReadyTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.restart = &cfg
+}
+
+// Restart 启动新进程并传递所有监听器，等待新进程就绪
+// 新进程使用相同地址调用Listen（或使用相同配置）时会继承对应的监听器而不是重新绑定，
+// 启动完成后通知当前进程。返回nil后当前进程应优雅关闭，收到重启信号时会自动完成
+// 返回值: error 启动新进程失败、新进程提前退出或就绪超时的错误信息
+func (s *Server) Restart() error {
+	s.mu.Lock()
+	cfg := DefaultRestartConfig
+	if s.restart != nil {
+		cfg = *s.restart
+	}
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	files, names, err := listenerFiles(listeners)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	path := cfg.Path
+	if path == "" {
+		if path, err = os.Executable(); err != nil {
+			return fmt.Errorf("failed to find executable: %w", err)
+		}
+	}
+	args := cfg.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	ready, notify, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create ready pipe: %w", err)
+	}
+	defer ready.Close()
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(files, notify)
+	cmd.Env = append(restartEnv(os.Environ()),
+		envListenFds+"="+encodeListenNames(names),
+		envReadyFd+"="+strconv.Itoa(listenFdsStart+len(files)),
+	)
+	err = cmd.Start()
+	notify.Close()
+	if err != nil {
+		return fmt.Errorf("failed to start new process: %w", err)
+	}
+
+	readyCh := make(chan error, 1)
+	go func() {
+		_, err := ready.Read(make([]byte, 1))
+		readyCh <- err
+	}()
+	select {
+	case err = <-readyCh:
+		if err != nil {
+			err = errors.New("new process exited before ready")
+		}
+	case <-time.After(cfg.ReadyTimeout):
+		err = fmt.Errorf("new process not ready after %s", cfg.ReadyTimeout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+
+	// 新进程已接管监听器，关闭时不再删除Unix socket文件
+	s.mu.Lock()
+	s.handedOff = true
+	s.mu.Unlock()
+	for _, l := range listeners {
+		if ul, ok := l.bound.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	return nil
+}
+
+// watchRestart 监听重启信号
+// 返回值: <-chan os.Signal 重启信号通道，未启用平滑重启或没有配置信号时为nil
+// 返回值: func() 停止监听
+func (s *Server) watchRestart() (<-chan os.Signal, func()) {
+	s.mu.Lock()
+	cfg := s.restart
+	s.mu.Unlock()
+	// 没有信号时signal.Notify会订阅所有信号，与关机信号冲突
+	if cfg == nil || len(cfg.Signals) == 0 {
+		return nil, func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, cfg.Signals...)
+	return ch, func() { signal.Stop(ch) }
+}
+
+// restartOnSignal 收到重启信号后执行重启
+// 返回值: bool 重启是否成功，成功时当前进程应优雅关闭
+func (s *Server) restartOnSignal() bool {
+	err := s.Restart()
+	if err == nil {
+		return true
+	}
+	s.mu.Lock()
+	onError := s.restart.OnError
+	s.mu.Unlock()
+	if onError != nil {
+		onError(err)
+	} else {
+		fmt.Fprintf(gin.DefaultErrorWriter, "[chi] graceful restart failed: %v\n", err)
+	}
+	return false
+}
+
+// isHandedOff 监听器是否已交给新进程
+func (s *Server) isHandedOff() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handedOff
+}
+
+// listenerFiles 复制所有已绑定监听器的文件描述符
+// 返回值: []*os.File 文件列表，调用方负责关闭
+// 返回值: []string 对应的监听地址
+func listenerFiles(listeners []*listener) ([]*os.File, []string, error) {
+	var files []*os.File
+	var names []string
+	add := func(name string, v any) error {
+		f, ok := v.(filer)
+		if !ok {
+			return fmt.Errorf("listener %s does not support handoff", name)
+		}
+		file, err := f.File()
+		if err != nil {
+			return fmt.Errorf("failed to get file of listener %s: %w", name, err)
+		}
+		files = append(files, file)
+		names = append(names, name)
+		return nil
+	}
+	for _, l := range listeners {
+		if l.bound == nil {
+			continue
+		}
+		if err := add(l.addr, l.bound); err != nil {
+			return files, nil, err
+		}
+		if l.packetConn != nil {
+			if err := add(l.addr+udpSuffix, l.packetConn); err != nil {
+				return files, nil, err
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil, errors.New("no listeners to hand off")
+	}
+	return files, names, nil
+}
+
+// encodeListenNames 编码继承的监听器地址
+// Unix socket路径等地址可能包含逗号，每个地址经过URL编码后再以逗号连接
+func encodeListenNames(names []string) string {
+	encoded := make([]string, len(names))
+	for i, name := range names {
+		encoded[i] = url.QueryEscape(name)
+	}
+	return strings.Join(encoded, ",")
+}
+
+// decodeListenNames 解码encodeListenNames编码的监听器地址
+func decodeListenNames(value string) []string {
+	names := strings.Split(value, ",")
+	for i, name := range names {
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			names[i] = decoded
+		}
+	}
+	return names
+}
+
+// restartEnv 去掉上一次重启传递的环境变量
+func restartEnv(env []string) []string {
+	result := make([]string, 0, len(env))
+	for _, kv := range env {
+		if strings.HasPrefix(kv, envListenFds+"=") || strings.HasPrefix(kv, envReadyFd+"=") {
+			continue
+		}
+		result = append(result, kv)
+	}
+	return result
+}
+
+// =============================================================================
+// 新进程
+// =============================================================================
+
+// loadInherited 读取从父进程继承的文件描述符
+func loadInherited() {
+	inherited.files = make(map[string]*os.File)
+	value := os.Getenv(envListenFds)
+	if value == "" {
+		return
+	}
+	os.Unsetenv(envListenFds)
+	for i, n := range decodeListenNames(value) {
+		fd := listenFdsStart + i
+		inherited.files[n] = os.NewFile(uintptr(fd), fmt.Sprintf("fd@%d", fd))
+	}
+}
+
+// inheritedFile 获取从父进程继承的文件，每个文件只能获取一次
+// 参数 name: 监听地址
+// 返回值: *os.File 继承的文件，不存在时为nil
+func inheritedFile(name string) *os.File {
+	inherited.once.Do(loadInherited)
+
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	f := inherited.files[name]
+	delete(inherited.files, name)
+	return f
+}
+
+// closeInherited 关闭没有被任何监听器获取的继承文件
+// 新进程删除了部分监听地址时，父进程传递的对应监听器不再使用，关闭后端口才会释放
+func closeInherited() {
+	inherited.once.Do(loadInherited)
+
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+	for name, f := range inherited.files {
+		f.Close()
+		delete(inherited.files, name)
+	}
+}
+
+// inheritListener 获取从父进程继承的监听器
+// 返回值: net.Listener 继承的监听器，不存在时为nil
+func inheritListener(addr string) (net.Listener, error) {
+	f := inheritedFile(addr)
+	if f == nil {
+		return nil, nil
+	}
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+// inheritPacketConn 获取从父进程继承的UDP连接
+// 返回值: net.PacketConn 继承的连接，不存在时为nil
+func inheritPacketConn(addr string) (net.PacketConn, error) {
+	f := inheritedFile(addr + udpSuffix)
+	if f == nil {
+		return nil, nil
+	}
+	defer f.Close()
+	return net.FilePacketConn(f)
+}
+
+// notifyReady 通知父进程新进程已就绪
+func notifyReady() {
+	value := os.Getenv(envReadyFd)
+	if value == "" {
+		return
+	}
+	os.Unsetenv(envReadyFd)
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	f.Write([]byte{1})
+	f.Close()
+}
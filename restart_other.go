@@ -0,0 +1,8 @@
+//go:build !unix
+
+package chi
+
+import "os"
+
+// restartSignals 当前平台不支持通过信号触发平滑重启
+var restartSignals []os.Signal
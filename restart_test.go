@@ -0,0 +1,134 @@
+//go:build unix
+
+package chi
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRestartChild 作为TestGracefulRestart启动的新进程运行
+func TestRestartChild(t *testing.T) {
+	addr := os.Getenv("CHI_TEST_RESTART_ADDR")
+	if addr == "" {
+		t.Skip("only runs as the new process of TestGracefulRestart")
+	}
+
+	s := newTestServer()
+	done := make(chan struct{})
+	s.GET("/who", func(c *Context) { c.String(http.StatusOK, "child") })
+	s.GET("/stop", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+		close(done)
+	})
+	require.NoError(t, s.Listen(addr))
+	if path := os.Getenv("CHI_TEST_RESTART_UNIX"); path != "" {
+		require.NoError(t, s.Listen("unix:"+path))
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+	}
+	s.quit <- syscall.SIGTERM
+	require.NoError(t, <-errCh)
+}
+
+func TestGracefulRestart(t *testing.T) {
+	s := newTestServer()
+	s.GET("/who", func(c *Context) { c.String(http.StatusOK, "parent") })
+	// 地址中的逗号不影响监听器的传递
+	socket := filepath.Join(t.TempDir(), "a,b.sock")
+	require.NoError(t, s.Listen("127.0.0.1:0"))
+	require.NoError(t, s.Listen("unix:"+socket))
+	require.NoError(t, s.Listen("localhost:0"))
+	t.Setenv("CHI_TEST_RESTART_ADDR", "127.0.0.1:0")
+	t.Setenv("CHI_TEST_RESTART_UNIX", socket)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 3 }, time.Second, 10*time.Millisecond)
+	base := "http://" + s.Addrs()[0].String()
+	removed := s.Addrs()[2].String()
+
+	get := func(path string) string {
+		resp, err := http.Get(base + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	assert.Equal(t, "parent", get("/who"))
+
+	// 新进程启动失败时继续使用当前进程
+	s.EnableGracefulRestart(RestartConfig{Path: "/bin/false", Args: []string{}})
+	assert.ErrorContains(t, s.Restart(), "exited before ready")
+	assert.Equal(t, "parent", get("/who"))
+
+	s.EnableGracefulRestart(RestartConfig{Path: os.Args[0], Args: []string{"-test.run=^TestRestartChild$"}})
+	require.NoError(t, s.Restart())
+	s.quit <- syscall.SIGTERM
+	require.NoError(t, <-errCh)
+
+	// 新进程在同一个监听器上继续服务
+	http.DefaultClient.CloseIdleConnections()
+	assert.Equal(t, "child", get("/who"))
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	_, err = io.WriteString(conn, "GET /who HTTP/1.0\r\nHost: localhost\r\n\r\n")
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	conn.Close()
+	assert.Equal(t, "child", string(body))
+
+	// 新进程没有使用的监听器被关闭
+	_, err = net.DialTimeout("tcp", removed, time.Second)
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+
+	assert.Equal(t, "ok", get("/stop"))
+}
+
+func TestRestartEnv(t *testing.T) {
+	env := restartEnv([]string{"PATH=/bin", envListenFds + "=:8080", envReadyFd + "=4", "HOME=/root"})
+	assert.Equal(t, []string{"PATH=/bin", "HOME=/root"}, env)
+
+	names := []string{":8080", "unix:/run/a,b.sock", "[::1]:443#udp", "unix:/tmp/100%.sock"}
+	assert.Equal(t, names, decodeListenNames(encodeListenNames(names)))
+
+	_, _, err := listenerFiles(nil)
+	assert.Error(t, err)
+}
+
+func TestWatchRestartNoSignals(t *testing.T) {
+	s := New()
+	ch, stop := s.watchRestart()
+	assert.Nil(t, ch)
+	stop()
+
+	// 不支持信号的平台上没有重启信号，不应订阅所有信号
+	s.EnableGracefulRestart()
+	s.restart.Signals = nil
+	ch, stop = s.watchRestart()
+	assert.Nil(t, ch)
+	stop()
+
+	s.EnableGracefulRestart()
+	ch, stop = s.watchRestart()
+	assert.NotNil(t, ch)
+	stop()
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package chi
+
+import (
+	"os"
+	"syscall"
+)
+
+// restartSignals 默认触发平滑重启的信号
+var restartSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
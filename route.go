@@ -0,0 +1,87 @@
+package chi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteOption 路由选项
+// 在注册路由时传入，为单个路由设置超时等配置
+type RouteOption func(*routeConfig)
+
+// routeConfig 路由配置
+type routeConfig struct {
+	// middleware 在处理函数之前执行的路由级中间件
+	middleware []gin.HandlerFunc
+	// meta 路由元数据，请求处理时通过Context.RouteMeta读取
+	meta map[string]any
+}
+
+// routeMetaKey 在gin.Context中保存路由元数据的键
+const routeMetaKey = "chi.route_meta"
+
+// routeHandlers 根据路由选项生成处理链
+func routeHandlers(handler HandlerFunc, opts []RouteOption) []gin.HandlerFunc {
+	if len(opts) == 0 {
+		return []gin.HandlerFunc{wrapHandler(handler)}
+	}
+	cfg := &routeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	handlers := make([]gin.HandlerFunc, 0, len(cfg.middleware)+2)
+	if len(cfg.meta) > 0 {
+		// 元数据最先写入，路由级中间件可以读取
+		meta := cfg.meta
+		handlers = append(handlers, func(c *gin.Context) {
+			c.Set(routeMetaKey, meta)
+		})
+	}
+	handlers = append(handlers, cfg.middleware...)
+	return append(handlers, wrapHandler(handler))
+}
+
+// Meta 为路由添加元数据
+// 元数据在路由级中间件和处理函数中通过Context.RouteMeta读取，
+// 全局和路由组中间件在路由元数据写入之前执行，无法读取
+// 参数 key: 元数据键
+// 参数 value: 元数据值
+func Meta(key string, value any) RouteOption {
+	return func(cfg *routeConfig) {
+		if cfg.meta == nil {
+			cfg.meta = make(map[string]any)
+		}
+		cfg.meta[key] = value
+	}
+}
+
+// RouteMeta 获取当前路由的元数据
+// 参数 key: 元数据键
+// 返回值: any 元数据值
+// 返回值: bool 是否存在
+func (c *Context) RouteMeta(key string) (any, bool) {
+	v, ok := c.Context.Get(routeMetaKey)
+	if !ok {
+		return nil, false
+	}
+	value, ok := v.(map[string]any)[key]
+	return value, ok
+}
+
+// WriteTimeout 覆盖路由的响应写入超时
+// 用于长轮询、SSE、大文件下载等需要比服务器WriteTimeout更长时间的路由
+// 参数 timeout: 写入超时时间，为0时取消写入超时
+func WriteTimeout(timeout time.Duration) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.middleware = append(cfg.middleware, func(c *gin.Context) {
+			var deadline time.Time
+			if timeout > 0 {
+				deadline = time.Now().Add(timeout)
+			}
+			// 底层连接不支持设置截止时间时（如httptest）忽略
+			_ = http.NewResponseController(c.Writer).SetWriteDeadline(deadline)
+		})
+	}
+}
@@ -12,6 +12,8 @@ import (
 type RouterGroup struct {
 	// group Gin的路由组实例
 	group *gin.RouterGroup
+	// server 所属的服务器实例
+	server *Server
 }
 
 // Group 创建子路由组
@@ -24,7 +26,8 @@ func (rg *RouterGroup) Group(relativePath string, middleware ...MiddlewareFunc)
 		subGroup.Use(wrapMiddleware(m))
 	}
 	return &RouterGroup{
-		group: subGroup,
+		group:  subGroup,
+		server: rg.server,
 	}
 }
 
@@ -41,64 +44,72 @@ func (rg *RouterGroup) Use(middleware ...MiddlewareFunc) {
 // 在当前路由组下注册GET请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) GET(relativePath string, handler HandlerFunc) {
-	rg.group.GET(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) GET(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.GET(relativePath, routeHandlers(handler, opts)...)
 }
 
 // POST 注册POST方法路由
 // 在当前路由组下注册POST请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) POST(relativePath string, handler HandlerFunc) {
-	rg.group.POST(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) POST(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.POST(relativePath, routeHandlers(handler, opts)...)
 }
 
 // PUT 注册PUT方法路由
 // 在当前路由组下注册PUT请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) PUT(relativePath string, handler HandlerFunc) {
-	rg.group.PUT(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) PUT(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.PUT(relativePath, routeHandlers(handler, opts)...)
 }
 
 // DELETE 注册DELETE方法路由
 // 在当前路由组下注册DELETE请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) DELETE(relativePath string, handler HandlerFunc) {
-	rg.group.DELETE(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) DELETE(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.DELETE(relativePath, routeHandlers(handler, opts)...)
 }
 
 // PATCH 注册PATCH方法路由
 // 在当前路由组下注册PATCH请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) PATCH(relativePath string, handler HandlerFunc) {
-	rg.group.PATCH(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) PATCH(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.PATCH(relativePath, routeHandlers(handler, opts)...)
 }
 
 // OPTIONS 注册OPTIONS方法路由
 // 在当前路由组下注册OPTIONS请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) OPTIONS(relativePath string, handler HandlerFunc) {
-	rg.group.OPTIONS(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) OPTIONS(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.OPTIONS(relativePath, routeHandlers(handler, opts)...)
 }
 
 // HEAD 注册HEAD方法路由
 // 在当前路由组下注册HEAD请求处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) HEAD(relativePath string, handler HandlerFunc) {
-	rg.group.HEAD(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) HEAD(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.HEAD(relativePath, routeHandlers(handler, opts)...)
 }
 
 // Any 注册所有HTTP方法的路由
 // 为指定路径注册所有常用HTTP方法的处理器
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) Any(relativePath string, handler HandlerFunc) {
-	rg.group.Any(relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) Any(relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.Any(relativePath, routeHandlers(handler, opts)...)
 }
 
 // Handle 注册指定HTTP方法的路由
@@ -106,8 +117,9 @@ func (rg *RouterGroup) Any(relativePath string, handler HandlerFunc) {
 // 参数 httpMethod: HTTP方法名（GET、POST等）
 // 参数 relativePath: 相对路径
 // 参数 handler: 处理函数
-func (rg *RouterGroup) Handle(httpMethod, relativePath string, handler HandlerFunc) {
-	rg.group.Handle(httpMethod, relativePath, wrapHandler(handler))
+// 参数 opts: 可选的路由选项，如WriteTimeout
+func (rg *RouterGroup) Handle(httpMethod, relativePath string, handler HandlerFunc, opts ...RouteOption) {
+	rg.group.Handle(httpMethod, relativePath, routeHandlers(handler, opts)...)
 }
 
 // Static 注册静态文件服务路由
@@ -149,3 +161,8 @@ func (rg *RouterGroup) StaticFileFS(relativePath, filepath string, fs http.FileS
 func (rg *RouterGroup) BasePath() string {
 	return rg.group.BasePath()
 }
+
+// catalog 获取所属服务器的端点目录
+func (rg *RouterGroup) catalog() *endpointCatalog {
+	return rg.server.endpoints
+}
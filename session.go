@@ -0,0 +1,59 @@
+package chi
+
+// =============================================================================
+// 会话
+// =============================================================================
+
+// Session 请求的会话，由middlewares.Session中间件创建
+// 修改会在响应头写入前自动保存
+type Session interface {
+	// ID 会话ID
+	ID() string
+	// IsNew 是否为本次请求新创建的会话
+	IsNew() bool
+	// Get 获取会话中的值
+	Get(key string) (any, bool)
+	// Set 设置会话中的值，自定义类型需要通过gob.Register注册
+	Set(key string, value any)
+	// Delete 删除会话中的值
+	Delete(key string)
+	// Clear 清空会话中的所有值
+	Clear()
+	// AddFlash 添加一次性消息，在下一次调用Flashes时取出
+	AddFlash(value any)
+	// Flashes 取出并清空所有一次性消息
+	Flashes() []any
+	// Regenerate 更换会话ID并保留数据，登录等权限变化时调用以防止会话固定攻击
+	Regenerate()
+	// Destroy 销毁会话，删除存储中的数据和Cookie
+	Destroy()
+}
+
+// SessionKey 当前请求的会话
+var SessionKey = NewKey[Session]("session")
+
+// Session 获取当前请求的会话
+// 返回值: Session 会话，未使用middlewares.Session中间件时为nil
+func (c *Context) Session() Session {
+	s, _ := GetValue(c, SessionKey)
+	return s
+}
+
+// SessionValue 获取会话中类型化的值
+// 参数 c: 请求上下文
+// 参数 key: 会话键
+// 返回值: T 会话中的值，不存在、类型不匹配或没有会话时为零值
+// 返回值: bool 值是否存在且类型匹配
+func SessionValue[T any](c *Context, key string) (T, bool) {
+	var zero T
+	s := c.Session()
+	if s == nil {
+		return zero, false
+	}
+	v, ok := s.Get(key)
+	if !ok {
+		return zero, false
+	}
+	value, ok := v.(T)
+	return value, ok
+}
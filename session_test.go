@@ -0,0 +1,50 @@
+package chi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapSession 测试用的内存会话
+type mapSession map[string]any
+
+func (s mapSession) ID() string                 { return "test" }
+func (s mapSession) IsNew() bool                { return false }
+func (s mapSession) Get(key string) (any, bool) { v, ok := s[key]; return v, ok }
+func (s mapSession) Set(key string, value any)  { s[key] = value }
+func (s mapSession) Delete(key string)          { delete(s, key) }
+func (s mapSession) Clear()                     { clear(s) }
+func (s mapSession) AddFlash(value any)         {}
+func (s mapSession) Flashes() []any             { return nil }
+func (s mapSession) Regenerate()                {}
+func (s mapSession) Destroy()                   {}
+
+func TestContextSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := New()
+	s.GET("/none", func(c *Context) {
+		assert.Nil(t, c.Session())
+		_, ok := SessionValue[string](c, "user_id")
+		assert.False(t, ok)
+		c.Status(http.StatusNoContent)
+	})
+	s.GET("/session", func(c *Context) {
+		SetValue(c, SessionKey, Session(mapSession{"user_id": "u1", "count": 3}))
+		id, ok := SessionValue[string](c, "user_id")
+		assert.True(t, ok)
+		assert.Equal(t, "u1", id)
+		_, ok = SessionValue[string](c, "count")
+		assert.False(t, ok)
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/none", "/session"} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	}
+}
@@ -0,0 +1,402 @@
+package chi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// defaultReloadInterval 默认的证书文件变化检查间隔
+const defaultReloadInterval = 10 * time.Second
+
+// TLSManager TLS证书管理器
+// 通过tls.Config.GetCertificate提供证书：按SNI选择证书，证书文件变化时自动重新加载
+// （适用于cert-manager等工具轮换的证书），启用ACME时自动申请和续期证书
+type TLSManager struct {
+	config TLSConfig
+	// certs 从文件加载的证书，第一个为默认证书
+	certs *certStore
+	// acme ACME证书管理器，未启用ACME时为nil
+	acme *autocert.Manager
+	// tlsConfig 基础TLS配置
+	tlsConfig *tls.Config
+}
+
+// certStore 可热加载的证书集合
+type certStore struct {
+	mu sync.RWMutex
+	// entries 证书列表
+	entries []*certEntry
+	// interval 文件变化检查间隔，小于0时不检查
+	interval time.Duration
+	// checked 上次检查文件变化的时间
+	checked time.Time
+}
+
+// certEntry 证书文件及加载的证书
+type certEntry struct {
+	certFile, keyFile string
+	// modTime 证书和私钥文件中较新的修改时间
+	modTime time.Time
+	cert    *tls.Certificate
+}
+
+// tlsVersions 支持的最低TLS版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuthTypes 支持的客户端证书验证方式
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// =============================================================================
+// 构造函数
+// =============================================================================
+
+// NewTLSManager 创建TLS证书管理器
+// 立即加载所有证书文件，任一文件无法加载时返回错误
+// 参数 config: TLS配置
+// 返回值: *TLSManager TLS证书管理器
+// 返回值: error 配置无效或证书加载失败时的错误信息
+func NewTLSManager(config TLSConfig) (*TLSManager, error) {
+	m := &TLSManager{config: config}
+
+	interval := config.ReloadInterval
+	if interval == 0 {
+		interval = defaultReloadInterval
+	}
+	m.certs = &certStore{interval: interval, checked: time.Now()}
+	if config.CertFile != "" {
+		m.certs.entries = append(m.certs.entries, &certEntry{certFile: config.CertFile, keyFile: config.KeyFile})
+	}
+	for _, cert := range config.Certificates {
+		m.certs.entries = append(m.certs.entries, &certEntry{certFile: cert.CertFile, keyFile: cert.KeyFile})
+	}
+	if err := m.certs.reload(true); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.MinVersion != "" {
+		version, ok := tlsVersions[config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls min_version %s", config.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	suites, err := cipherSuites(config.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.CipherSuites = suites
+
+	if config.ClientCAFile != "" {
+		data, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls client_ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in tls client_ca_file %s", config.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if config.ClientAuth != "" {
+		auth, ok := clientAuthTypes[config.ClientAuth]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls client_auth %s", config.ClientAuth)
+		}
+		tlsConfig.ClientAuth = auth
+	}
+
+	if config.ACME.Enabled {
+		if m.acme, err = newACMEManager(config.ACME); err != nil {
+			return nil, err
+		}
+		tlsConfig.NextProtos = []string{"h2", "http/1.1", acme.ALPNProto}
+	} else if len(m.certs.entries) == 0 {
+		return nil, errors.New("tls requires at least one certificate or acme")
+	}
+	tlsConfig.GetCertificate = m.GetCertificate
+	m.tlsConfig = tlsConfig
+	return m, nil
+}
+
+// newACMEManager 创建ACME证书管理器
+func newACMEManager(config ACMEConfig) (*autocert.Manager, error) {
+	if len(config.Domains) == 0 {
+		return nil, errors.New("tls acme domains are required when acme is enabled")
+	}
+	cacheDir := config.CacheDir
+	if cacheDir == "" {
+		cacheDir = "./certs"
+	}
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(config.Domains...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      config.Email,
+	}
+	if config.DirectoryURL != "" || config.CAFile != "" {
+		client := &acme.Client{DirectoryURL: config.DirectoryURL}
+		if config.CAFile != "" {
+			data, err := os.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read tls acme ca_file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificates found in tls acme ca_file %s", config.CAFile)
+			}
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+			client.HTTPClient = &http.Client{Transport: transport}
+		}
+		manager.Client = client
+	}
+	return manager, nil
+}
+
+// cipherSuites 将密码套件名称转换为ID
+func cipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls cipher suite %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// =============================================================================
+// 证书管理
+// =============================================================================
+
+// TLSConfig 获取使用该管理器提供证书的TLS配置
+// 每次调用返回新的副本，可以传给WithTLSConfig或http.Server
+// 返回值: *tls.Config TLS配置
+func (m *TLSManager) TLSConfig() *tls.Config {
+	return m.tlsConfig.Clone()
+}
+
+// GetCertificate 根据TLS握手信息选择证书
+// 先检查证书文件是否变化，然后选择第一个支持客户端SNI的证书；
+// 启用ACME时，ACME验证请求和未匹配文件证书的域名交给ACME处理；否则使用默认证书
+// 参数 hello: TLS握手信息
+// 返回值: *tls.Certificate 证书
+// 返回值: error 无可用证书时的错误信息
+func (m *TLSManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if m.acme != nil && slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+		return m.acme.GetCertificate(hello)
+	}
+	m.certs.maybeReload()
+	cert, matched := m.certs.match(hello)
+	if m.acme != nil && !matched {
+		return m.acme.GetCertificate(hello)
+	}
+	if cert == nil {
+		return nil, errors.New("no tls certificate available")
+	}
+	return cert, nil
+}
+
+// Reload 立即重新加载所有证书文件
+// 任一文件加载失败时保留该证书的旧版本并返回错误
+// 返回值: error 证书加载失败时的错误信息
+func (m *TLSManager) Reload() error {
+	return m.certs.reload(true)
+}
+
+// HTTPHandler 获取处理ACME HTTP-01验证的HTTP处理器
+// 通常注册在80端口的监听器上：server.Listen(":80", chi.WithHandler(m.HTTPHandler(nil)))。
+// 未启用ACME时直接使用fallback
+// 参数 fallback: 处理非验证请求的处理器，为nil时重定向到HTTPS
+// 返回值: http.Handler HTTP处理器
+func (m *TLSManager) HTTPHandler(fallback http.Handler) http.Handler {
+	if m.acme != nil {
+		return m.acme.HTTPHandler(fallback)
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return http.HandlerFunc(redirectHTTPS)
+}
+
+// redirectHTTPS 将请求重定向到HTTPS
+func redirectHTTPS(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "Use HTTPS", http.StatusBadRequest)
+		return
+	}
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	http.Redirect(w, req, "https://"+host+req.URL.RequestURI(), http.StatusFound)
+}
+
+// match 选择支持客户端握手信息的证书
+// 返回值: *tls.Certificate 匹配的证书，未匹配时为默认证书
+// 返回值: bool 是否匹配
+func (s *certStore) match(hello *tls.ClientHelloInfo) (*tls.Certificate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.entries) == 0 {
+		return nil, false
+	}
+	for _, entry := range s.entries {
+		if hello.SupportsCertificate(entry.cert) == nil {
+			return entry.cert, true
+		}
+	}
+	return s.entries[0].cert, false
+}
+
+// maybeReload 距上次检查超过检查间隔时重新加载变化的证书文件
+// 加载失败时继续使用旧证书，等待下次检查
+func (s *certStore) maybeReload() {
+	if s.interval < 0 {
+		return
+	}
+	s.mu.RLock()
+	due := time.Since(s.checked) >= s.interval
+	s.mu.RUnlock()
+	if due {
+		_ = s.reload(false)
+	}
+}
+
+// reload 重新加载证书文件
+// 参数 force: 为true时重新加载所有文件，否则只加载修改时间变化的文件
+func (s *certStore) reload(force bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checked = time.Now()
+
+	var errs []error
+	for _, entry := range s.entries {
+		modTime, err := latestModTime(entry.certFile, entry.keyFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to stat tls certificate %s: %w", entry.certFile, err))
+			continue
+		}
+		if !force && entry.cert != nil && modTime.Equal(entry.modTime) {
+			continue
+		}
+		cert, err := tls.LoadX509KeyPair(entry.certFile, entry.keyFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to load tls certificate %s: %w", entry.certFile, err))
+			continue
+		}
+		entry.cert, entry.modTime = &cert, modTime
+	}
+	return errors.Join(errs...)
+}
+
+// latestModTime 获取多个文件中较新的修改时间，软链接指向新文件时也会变化
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// =============================================================================
+// Server集成
+// =============================================================================
+
+// TLS 获取根据配置创建的TLS证书管理器
+// 返回值: *TLSManager 未启用TLS时为nil
+func (s *Server) TLS() *TLSManager {
+	return s.tls
+}
+
+// newFileTLSConfig 使用证书文件创建支持热加载的TLS配置，并应用服务器配置中的TLS参数
+func (s *Server) newFileTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	config := s.cfg.Server.TLS
+	config.Enabled = true
+	config.CertFile, config.KeyFile = certFile, keyFile
+	config.Certificates = nil
+	config.ACME = ACMEConfig{}
+	m, err := NewTLSManager(config)
+	if err != nil {
+		return nil, err
+	}
+	return m.TLSConfig(), nil
+}
+
+// =============================================================================
+// 客户端证书
+// =============================================================================
+
+// ClientCertificate 获取已通过ClientCAFile验证的mTLS客户端证书
+// ClientAuth为"request"或"require"时证书不会被验证，客户端可以提供任意自签名证书，此时返回nil
+// 返回值: *x509.Certificate 客户端证书，非TLS请求、客户端未提供证书或证书未经验证时为nil
+func (c *Context) ClientCertificate() *x509.Certificate {
+	state := c.Context.Request.TLS
+	if state == nil || len(state.PeerCertificates) == 0 || len(state.VerifiedChains) == 0 {
+		return nil
+	}
+	return state.PeerCertificates[0]
+}
+
+// UnverifiedClientCertificate 获取客户端提供的证书，不论是否经过验证
+// 证书内容不可信，不能用于认证，只适合记录日志或由调用方自行验证
+// 返回值: *x509.Certificate 客户端证书，非TLS请求或客户端未提供证书时为nil
+// 返回值: bool 证书是否已通过验证
+func (c *Context) UnverifiedClientCertificate() (*x509.Certificate, bool) {
+	state := c.Context.Request.TLS
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil, false
+	}
+	return state.PeerCertificates[0], len(state.VerifiedChains) > 0
+}
+
+// ClientSubject 获取已验证的mTLS客户端证书的主题
+// 返回值: string 证书主题，如"CN=client,O=example"，无已验证的客户端证书时为空
+func (c *Context) ClientSubject() string {
+	if cert := c.ClientCertificate(); cert != nil {
+		return cert.Subject.String()
+	}
+	return ""
+}
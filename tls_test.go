@@ -0,0 +1,365 @@
+package chi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+// testCA 测试用CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+	file string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "chi test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return &testCA{cert: cert, key: key, pool: pool, file: file}
+}
+
+// issue 签发证书并写入文件
+func (ca *testCA) issue(t *testing.T, dir, cn string, serial int64, usage x509.ExtKeyUsage, hosts ...string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"chi"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     hosts,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, cn+".crt")
+	keyFile = filepath.Join(dir, cn+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+// peerCertificate 握手并返回服务端证书
+func peerCertificate(t *testing.T, addr, serverName string, pool *x509.CertPool) *x509.Certificate {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: serverName, RootCAs: pool})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0]
+}
+
+func TestTLSManagerSNI(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certA, keyA := ca.issue(t, dir, "a.test", 10, x509.ExtKeyUsageServerAuth, "a.test")
+	certB, keyB := ca.issue(t, dir, "b.test", 20, x509.ExtKeyUsageServerAuth, "b.test", "*.b.test")
+
+	m, err := NewTLSManager(TLSConfig{
+		Enabled:      true,
+		CertFile:     certA,
+		KeyFile:      keyA,
+		Certificates: []CertificateConfig{{CertFile: certB, KeyFile: keyB}},
+	})
+	require.NoError(t, err)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", m.TLSConfig())
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+
+	addr := ln.Addr().String()
+	assert.Equal(t, "a.test", peerCertificate(t, addr, "a.test", ca.pool).Subject.CommonName)
+	assert.Equal(t, "b.test", peerCertificate(t, addr, "b.test", ca.pool).Subject.CommonName)
+	assert.Equal(t, "b.test", peerCertificate(t, addr, "api.b.test", ca.pool).Subject.CommonName)
+
+	// 未匹配的SNI使用默认证书
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: "other.test", InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.Equal(t, "a.test", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	conn.Close()
+}
+
+func TestTLSManagerReload(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.issue(t, dir, "localhost", 1, x509.ExtKeyUsageServerAuth, "localhost")
+
+	s := newTestServer()
+	s.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+	s.cfg.Server.TLS.ReloadInterval = time.Millisecond
+	require.NoError(t, s.Listen("127.0.0.1:0", WithTLS(certFile, keyFile)))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+	addr := s.Addrs()[0].String()
+
+	assert.Equal(t, int64(1), peerCertificate(t, addr, "localhost", ca.pool).SerialNumber.Int64())
+
+	// 模拟证书轮换：覆盖文件并更新修改时间
+	ca.issue(t, dir, "localhost", 2, x509.ExtKeyUsageServerAuth, "localhost")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, int64(2), peerCertificate(t, addr, "localhost", ca.pool).SerialNumber.Int64())
+
+	// 加载失败时继续使用旧证书
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0600))
+	later := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, int64(2), peerCertificate(t, addr, "localhost", ca.pool).SerialNumber.Int64())
+
+	require.NoError(t, s.Stop())
+	require.NoError(t, <-errCh)
+}
+
+func TestTLSManagerClientAuth(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.issue(t, dir, "localhost", 1, x509.ExtKeyUsageServerAuth, "localhost")
+	clientCert, clientKey := ca.issue(t, dir, "client", 2, x509.ExtKeyUsageClientAuth)
+
+	cfg := DefaultConfig()
+	cfg.Server.Upload = ""
+	cfg.Server.TLS = TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientCAFile: ca.file}
+	s, err := NewWithConfig(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, s.TLS())
+	s.GET("/whoami", func(c *Context) {
+		assert.NotNil(t, c.ClientCertificate())
+		c.String(http.StatusOK, c.ClientSubject())
+	})
+
+	ts := httptest.NewUnstartedServer(s)
+	ts.TLS = s.TLS().TLSConfig()
+	ts.StartTLS()
+	defer ts.Close()
+	url := ts.URL + "/whoami"
+
+	pair, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{ServerName: "localhost", RootCAs: ca.pool, Certificates: []tls.Certificate{pair}},
+	}}
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "CN=client,O=chi", string(body))
+
+	// 未提供客户端证书时握手失败
+	client = &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{ServerName: "localhost", RootCAs: ca.pool},
+	}}
+	_, err = client.Get(url)
+	assert.Error(t, err)
+}
+
+func TestClientCertificateUnverified(t *testing.T) {
+	ca := newTestCA(t)
+	other := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.issue(t, dir, "localhost", 1, x509.ExtKeyUsageServerAuth, "localhost")
+	// 由不受信任的CA签发，伪造为受信任客户端的主题
+	forgedCert, forgedKey := other.issue(t, t.TempDir(), "client", 2, x509.ExtKeyUsageClientAuth)
+
+	for _, mode := range []string{"request", "require"} {
+		cfg := DefaultConfig()
+		cfg.Server.Upload = ""
+		cfg.Server.TLS = TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientCAFile: ca.file, ClientAuth: mode}
+		s, err := NewWithConfig(cfg)
+		require.NoError(t, err)
+		s.GET("/whoami", func(c *Context) {
+			assert.Nil(t, c.ClientCertificate())
+			cert, verified := c.UnverifiedClientCertificate()
+			require.NotNil(t, cert)
+			assert.False(t, verified)
+			c.String(http.StatusOK, c.ClientSubject())
+		})
+
+		ts := httptest.NewUnstartedServer(s)
+		ts.TLS = s.TLS().TLSConfig()
+		ts.StartTLS()
+
+		pair, err := tls.LoadX509KeyPair(forgedCert, forgedKey)
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{ServerName: "localhost", RootCAs: ca.pool, Certificates: []tls.Certificate{pair}},
+		}}
+		resp, err := client.Get(ts.URL + "/whoami")
+		require.NoError(t, err, mode)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, string(body), mode)
+		ts.Close()
+	}
+}
+
+func TestTLSManagerConfig(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, t.TempDir(), "localhost", 1, x509.ExtKeyUsageServerAuth, "localhost")
+	base := TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile}
+
+	config := base
+	config.MinVersion = "1.3"
+	config.CipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}
+	config.ClientAuth = "request"
+	m, err := NewTLSManager(config)
+	require.NoError(t, err)
+	tlsConfig := m.TLSConfig()
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, tlsConfig.CipherSuites)
+	assert.Equal(t, tls.RequestClientCert, tlsConfig.ClientAuth)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+
+	m, err = NewTLSManager(base)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), m.TLSConfig().MinVersion)
+
+	for _, modify := range []func(*TLSConfig){
+		func(c *TLSConfig) { c.MinVersion = "2.0" },
+		func(c *TLSConfig) { c.CipherSuites = []string{"TLS_UNKNOWN"} },
+		func(c *TLSConfig) { c.ClientAuth = "always" },
+		func(c *TLSConfig) { c.ClientCAFile = certFile + ".missing" },
+		func(c *TLSConfig) { c.KeyFile = certFile },
+		func(c *TLSConfig) { c.CertFile, c.KeyFile = "", "" },
+	} {
+		config := base
+		modify(&config)
+		_, err := NewTLSManager(config)
+		assert.Error(t, err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Server.TLS = TLSConfig{Enabled: true, ACME: ACMEConfig{Enabled: true}}
+	assert.Error(t, cfg.Validate())
+	cfg.Server.TLS.ACME.Domains = []string{"example.com"}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "./certs", cfg.Server.TLS.ACME.CacheDir)
+}
+
+func TestTLSManagerACME(t *testing.T) {
+	m, err := NewTLSManager(TLSConfig{
+		Enabled: true,
+		ACME: ACMEConfig{
+			Enabled:      true,
+			Domains:      []string{"example.com"},
+			CacheDir:     t.TempDir(),
+			DirectoryURL: "https://127.0.0.1:14000/dir",
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, m.TLSConfig().NextProtos, acme.ALPNProto)
+	assert.Equal(t, "https://127.0.0.1:14000/dir", m.acme.Client.DirectoryURL)
+
+	// 不在域名白名单中的主机被拒绝，不会请求ACME服务
+	_, err = m.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"})
+	assert.Error(t, err)
+
+	// 非验证请求重定向到HTTPS
+	w := httptest.NewRecorder()
+	m.HTTPHandler(nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/path", nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://example.com/path", w.Header().Get("Location"))
+}
+
+// TestTLSManagerPebble 使用本地pebble服务测试ACME证书申请
+// 需要先启动pebble，例如：pebble -config test/config/pebble-config.json，
+// 并设置CHI_TEST_ACME_DIRECTORY（如https://localhost:14000/dir）和CHI_TEST_ACME_CA（pebble.minica.pem），
+// pebble默认通过5001端口进行TLS-ALPN-01验证
+func TestTLSManagerPebble(t *testing.T) {
+	directory := os.Getenv("CHI_TEST_ACME_DIRECTORY")
+	if directory == "" {
+		t.Skip("CHI_TEST_ACME_DIRECTORY not set")
+	}
+	domain := os.Getenv("CHI_TEST_ACME_DOMAIN")
+	if domain == "" {
+		domain = "localhost"
+	}
+
+	m, err := NewTLSManager(TLSConfig{
+		Enabled: true,
+		ACME: ACMEConfig{
+			Enabled:      true,
+			Domains:      []string{domain},
+			Email:        "admin@example.com",
+			CacheDir:     t.TempDir(),
+			DirectoryURL: directory,
+			CAFile:       os.Getenv("CHI_TEST_ACME_CA"),
+		},
+	})
+	require.NoError(t, err)
+
+	s := newTestServer()
+	s.GET("/ping", func(c *Context) { c.String(http.StatusOK, "pong") })
+	require.NoError(t, s.Listen(":5001", WithTLSConfig(m.TLSConfig())))
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.Serve() }()
+	require.Eventually(t, func() bool { return len(s.Addrs()) == 1 }, time.Second, 10*time.Millisecond)
+
+	conn, err := tls.Dial("tcp", "127.0.0.1:5001", &tls.Config{ServerName: domain, InsecureSkipVerify: true})
+	require.NoError(t, err)
+	cert := conn.ConnectionState().PeerCertificates[0]
+	conn.Close()
+	assert.Contains(t, cert.DNSNames, domain)
+	assert.NotEqual(t, cert.Subject.String(), cert.Issuer.String())
+
+	require.NoError(t, s.Stop())
+	require.NoError(t, <-errCh)
+}
@@ -0,0 +1,276 @@
+package chi
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// =============================================================================
+// 类型定义
+// =============================================================================
+
+// TypedHandlerFunc 类型化处理函数
+// 请求参数自动绑定到Req，返回值通过Res统一包装为Response响应
+type TypedHandlerFunc[Req, Resp any] func(*Context, Req) (Resp, error)
+
+// Router 路由注册接口
+// 由*Server和*RouterGroup实现，用于类型化路由的注册
+type Router interface {
+	// Handle 注册指定HTTP方法的路由
+	Handle(httpMethod, relativePath string, handler HandlerFunc, opts ...RouteOption)
+	// BasePath 获取路由的基础路径
+	BasePath() string
+	// catalog 获取端点目录，仅由本包实现
+	catalog() *endpointCatalog
+}
+
+// Endpoint 类型化端点描述
+// 记录通过类型化方法注册的路由及其请求、响应类型
+type Endpoint struct {
+	// Method HTTP方法
+	Method string
+	// Path 完整路由路径，如/api/v1/users/:id
+	Path string
+	// Request 请求参数类型
+	Request reflect.Type
+	// Response 响应数据类型
+	Response reflect.Type
+}
+
+// endpointCatalog 端点目录
+type endpointCatalog struct {
+	mu        sync.RWMutex
+	endpoints []Endpoint
+}
+
+// add 添加端点
+func (c *endpointCatalog) add(e Endpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints = append(c.endpoints, e)
+}
+
+// list 获取端点列表副本
+func (c *endpointCatalog) list() []Endpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	endpoints := make([]Endpoint, len(c.endpoints))
+	copy(endpoints, c.endpoints)
+	return endpoints
+}
+
+// =============================================================================
+// 类型化路由注册方法
+// =============================================================================
+
+// GETT 注册类型化GET路由
+// 参数 r: 路由注册器，*Server或*RouterGroup
+// 参数 relativePath: 路由路径
+// 参数 handler: 类型化处理函数
+// 参数 opts: 可选的路由选项
+func GETT[Req, Resp any](r Router, relativePath string, handler TypedHandlerFunc[Req, Resp], opts ...RouteOption) {
+	HandleT(r, http.MethodGet, relativePath, handler, opts...)
+}
+
+// POSTT 注册类型化POST路由
+// 参数 r: 路由注册器，*Server或*RouterGroup
+// 参数 relativePath: 路由路径
+// 参数 handler: 类型化处理函数
+// 参数 opts: 可选的路由选项
+func POSTT[Req, Resp any](r Router, relativePath string, handler TypedHandlerFunc[Req, Resp], opts ...RouteOption) {
+	HandleT(r, http.MethodPost, relativePath, handler, opts...)
+}
+
+// PUTT 注册类型化PUT路由
+// 参数 r: 路由注册器，*Server或*RouterGroup
+// 参数 relativePath: 路由路径
+// 参数 handler: 类型化处理函数
+// 参数 opts: 可选的路由选项
+func PUTT[Req, Resp any](r Router, relativePath string, handler TypedHandlerFunc[Req, Resp], opts ...RouteOption) {
+	HandleT(r, http.MethodPut, relativePath, handler, opts...)
+}
+
+// PATCHT 注册类型化PATCH路由
+// 参数 r: 路由注册器，*Server或*RouterGroup
+// 参数 relativePath: 路由路径
+// 参数 handler: 类型化处理函数
+// 参数 opts: 可选的路由选项
+func PATCHT[Req, Resp any](r Router, relativePath string, handler TypedHandlerFunc[Req, Resp], opts ...RouteOption) {
+	HandleT(r, http.MethodPatch, relativePath, handler, opts...)
+}
+
+// DELETET 注册类型化DELETE路由
+// 参数 r: 路由注册器，*Server或*RouterGroup
+// 参数 relativePath: 路由路径
+// 参数 handler: 类型化处理函数
+// 参数 opts: 可选的路由选项
+func DELETET[Req, Resp any](r Router, relativePath string, handler TypedHandlerFunc[Req, Resp], opts ...RouteOption) {
+	HandleT(r, http.MethodDelete, relativePath, handler, opts...)
+}
+
+// HandleT 注册指定HTTP方法的类型化路由
+// 按uri、header、form标签绑定路径参数、请求头和查询参数，存在请求体时按Content-Type绑定请求体，
+// 绑定失败时响应ErrBinding，验证失败时附带字段级错误，否则调用处理函数并通过Res包装结果
+// 参数 r: 路由注册器，*Server或*RouterGroup
+// 参数 httpMethod: HTTP方法名称
+// 参数 relativePath: 路由路径
+// 参数 handler: 类型化处理函数
+// 参数 opts: 可选的路由选项
+func HandleT[Req, Resp any](r Router, httpMethod, relativePath string, handler TypedHandlerFunc[Req, Resp], opts ...RouteOption) {
+	r.catalog().add(Endpoint{
+		Method:   httpMethod,
+		Path:     joinPaths(r.BasePath(), relativePath),
+		Request:  reflect.TypeOf((*Req)(nil)).Elem(),
+		Response: reflect.TypeOf((*Resp)(nil)).Elem(),
+	})
+
+	r.Handle(httpMethod, relativePath, func(ctx *Context) {
+		var req Req
+		if err := bindRequest(ctx, &req); err != nil {
+			Res(ctx, bindingError(err))
+			return
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			Res(ctx, err)
+			return
+		}
+		Res(ctx, nil, resp)
+	}, opts...)
+}
+
+// =============================================================================
+// 请求绑定
+// =============================================================================
+
+// bindTags 结构体中声明的绑定标签
+type bindTags struct {
+	uri    bool
+	header []string
+	form   bool
+}
+
+// bindTagsCache 按类型缓存的绑定标签
+var bindTagsCache sync.Map
+
+// bindRequest 将请求绑定到obj
+// 依次映射路径参数、请求头、查询参数，最后绑定请求体并统一验证
+func bindRequest(ctx *Context, obj any) error {
+	t := reflect.TypeOf(obj).Elem()
+	if t.Kind() != reflect.Struct {
+		if hasBody(ctx.Request()) {
+			return ctx.ShouldBind(obj)
+		}
+		return nil
+	}
+
+	tags := lookupBindTags(t)
+	req := ctx.Request()
+
+	if tags.uri {
+		params := make(map[string][]string, len(ctx.Params))
+		for _, p := range ctx.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+			return err
+		}
+	}
+
+	if len(tags.header) > 0 {
+		headers := make(map[string][]string, len(tags.header))
+		for _, name := range tags.header {
+			if values := req.Header.Values(name); len(values) > 0 {
+				headers[name] = values
+			}
+		}
+		if err := binding.MapFormWithTag(obj, headers, "header"); err != nil {
+			return err
+		}
+	}
+
+	if tags.form {
+		if err := binding.MapFormWithTag(obj, req.URL.Query(), "form"); err != nil {
+			return err
+		}
+	}
+
+	// 请求体绑定会对整个结构体执行验证
+	if hasBody(req) {
+		return ctx.ShouldBindWith(obj, binding.Default(req.Method, ctx.ContentType()))
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// hasBody 判断请求是否携带请求体
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
+
+// lookupBindTags 获取类型的绑定标签，结果按类型缓存
+func lookupBindTags(t reflect.Type) *bindTags {
+	if cached, ok := bindTagsCache.Load(t); ok {
+		return cached.(*bindTags)
+	}
+	tags := &bindTags{}
+	collectBindTags(t, tags)
+	actual, _ := bindTagsCache.LoadOrStore(t, tags)
+	return actual.(*bindTags)
+}
+
+// collectBindTags 递归收集结构体字段的绑定标签
+func collectBindTags(t reflect.Type, tags *bindTags) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectBindTags(ft, tags)
+				continue
+			}
+		}
+		if _, ok := field.Tag.Lookup("uri"); ok {
+			tags.uri = true
+		}
+		if name := tagName(field.Tag.Get("header")); name != "" && name != "-" {
+			tags.header = append(tags.header, name)
+		}
+		if _, ok := field.Tag.Lookup("form"); ok {
+			tags.form = true
+		}
+	}
+}
+
+// tagName 获取标签值中逗号前的名称部分
+func tagName(tag string) string {
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			return tag[:i]
+		}
+	}
+	return tag
+}
+
+// joinPaths 拼接基础路径和相对路径，保留相对路径的尾部斜杠
+func joinPaths(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	finalPath := path.Join(basePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
@@ -0,0 +1,108 @@
+package chi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type updateUserReq struct {
+	ID      int    `uri:"id" binding:"required"`
+	TraceID string `header:"X-Trace-ID"`
+	Notify  bool   `form:"notify"`
+	Name    string `json:"name" binding:"required"`
+}
+
+type userResp struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	TraceID string `json:"trace_id"`
+	Notify  bool   `json:"notify"`
+}
+
+func newTestServer() *Server {
+	gin.SetMode(gin.TestMode)
+	return New()
+}
+
+func doRequest(s *Server, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body
+}
+
+func TestTypedHandlers(t *testing.T) {
+	s := newTestServer()
+	api := s.Group("/api")
+
+	PUTT(api, "/users/:id", func(c *Context, req updateUserReq) (userResp, error) {
+		return userResp{ID: req.ID, Name: req.Name, TraceID: req.TraceID, Notify: req.Notify}, nil
+	})
+	GETT(s, "/fail", func(c *Context, req struct{}) (any, error) {
+		return nil, NewError(404, "not found")
+	})
+
+	t.Run("BindAllSources", func(t *testing.T) {
+		w := doRequest(s, http.MethodPut, "/api/users/7?notify=true", `{"name":"tom"}`, map[string]string{"X-Trace-ID": "abc"})
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(200), body["code"])
+		assert.Equal(t, map[string]interface{}{
+			"id": float64(7), "name": "tom", "trace_id": "abc", "notify": true,
+		}, body["data"])
+	})
+
+	t.Run("ValidationFailure", func(t *testing.T) {
+		w := doRequest(s, http.MethodPut, "/api/users/7", `{}`, nil)
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(ErrBinding.Code), body["code"])
+		require.Len(t, body["data"], 1)
+		assert.Equal(t, "name", body["data"].([]interface{})[0].(map[string]interface{})["field"])
+	})
+
+	t.Run("MalformedBody", func(t *testing.T) {
+		w := doRequest(s, http.MethodPut, "/api/users/7", `{`, nil)
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(ErrBinding.Code), body["code"])
+		assert.Nil(t, body["data"])
+	})
+
+	t.Run("HandlerError", func(t *testing.T) {
+		w := doRequest(s, http.MethodGet, "/fail", "", nil)
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(404), body["code"])
+		assert.Equal(t, "not found", body["message"])
+	})
+
+	t.Run("Endpoints", func(t *testing.T) {
+		endpoints := s.Endpoints()
+		require.Len(t, endpoints, 2)
+		assert.Equal(t, http.MethodPut, endpoints[0].Method)
+		assert.Equal(t, "/api/users/:id", endpoints[0].Path)
+		assert.Equal(t, reflect.TypeOf(updateUserReq{}), endpoints[0].Request)
+		assert.Equal(t, reflect.TypeOf(userResp{}), endpoints[0].Response)
+	})
+}
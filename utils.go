@@ -1,23 +1,60 @@
 package chi
 
-import "github.com/gin-gonic/gin"
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextKey 在gin.Context中保存chi.Context的键
+const contextKey = "chi.context"
+
+// contextPool chi.Context对象池
+// 每个请求从池中取出一个Context，由整个处理链共享，请求结束后归还。
+// 与gin.Context一样，Context不能在请求结束后继续使用，在goroutine中使用时需调用Copy
+var contextPool = sync.Pool{
+	New: func() any {
+		return &Context{}
+	},
+}
+
+// acquireContext 从对象池获取Context并关联到当前请求
+func acquireContext(ginCtx *gin.Context) *Context {
+	ctx := contextPool.Get().(*Context)
+	ctx.Context = ginCtx
+	ginCtx.Set(contextKey, ctx)
+	return ctx
+}
+
+// releaseContext 重置Context并归还对象池
+func releaseContext(ctx *Context) {
+	ctx.Context = nil
+	contextPool.Put(ctx)
+}
+
+// getContext 获取当前请求共享的Context
+// 未经过Server的全局中间件时（如直接挂载到gin引擎）创建新的Context并缓存
+func getContext(ginCtx *gin.Context) *Context {
+	if v, ok := ginCtx.Get(contextKey); ok {
+		if ctx, ok := v.(*Context); ok && ctx.Context == ginCtx {
+			return ctx
+		}
+	}
+	ctx := &Context{Context: ginCtx}
+	ginCtx.Set(contextKey, ctx)
+	return ctx
+}
 
 // wrapHandler 包装处理函数
 func wrapHandler(handler HandlerFunc) gin.HandlerFunc {
 	return func(ginCtx *gin.Context) {
-		ctx := &Context{
-			Context: ginCtx,
-		}
-		handler(ctx)
+		handler(getContext(ginCtx))
 	}
 }
 
 // wrapMiddleware 包装中间件函数
 func wrapMiddleware(middleware MiddlewareFunc) gin.HandlerFunc {
 	return func(ginCtx *gin.Context) {
-		ctx := &Context{
-			Context: ginCtx,
-		}
-		middleware(ctx)
+		middleware(getContext(ginCtx))
 	}
 }
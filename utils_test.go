@@ -0,0 +1,95 @@
+package chi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextShared(t *testing.T) {
+	s := newTestServer()
+	var seen []*Context
+	record := func(c *Context) {
+		seen = append(seen, c)
+		c.Next()
+	}
+	s.Use(record, record)
+	group := s.Group("/api", record)
+	group.GET("/ping", func(c *Context) {
+		seen = append(seen, c)
+		c.String(http.StatusOK, "pong")
+	})
+
+	w := doRequest(s, http.MethodGet, "/api/ping", "", nil)
+	assert.Equal(t, "pong", w.Body.String())
+	assert.Len(t, seen, 4)
+	for _, c := range seen {
+		assert.Same(t, seen[0], c)
+	}
+	// 请求结束后Context已归还对象池
+	assert.Nil(t, seen[0].Context)
+}
+
+func TestContextWithoutServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	var first, second *Context
+	engine.GET("/ping",
+		wrapMiddleware(func(c *Context) { first = c; c.Next() }),
+		wrapHandler(func(c *Context) { second = c; c.Status(http.StatusNoContent) }),
+	)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Same(t, first, second)
+}
+
+// BenchmarkHandlerChain 请求经过五个中间件时每个请求的分配次数
+func BenchmarkHandlerChain(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	s := New()
+	for i := 0; i < 5; i++ {
+		s.Use(func(c *Context) { c.Next() })
+	}
+	s.GET("/ping", func(c *Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.ServeHTTP(w, req)
+	}
+}
+
+// unpooledHandler 逐个分配Context的包装方式，用于对照
+//
+//go:noinline
+func unpooledHandler(handler HandlerFunc) gin.HandlerFunc {
+	return func(ginCtx *gin.Context) {
+		handler(&Context{Context: ginCtx})
+	}
+}
+
+// BenchmarkHandlerChainUnpooled 作为对照，每个处理函数分配独立的Context
+func BenchmarkHandlerChainUnpooled(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set(serverContextKey, nil) })
+	for i := 0; i < 5; i++ {
+		engine.Use(unpooledHandler(func(c *Context) { c.Next() }))
+	}
+	engine.GET("/ping", unpooledHandler(func(c *Context) { c.Status(http.StatusNoContent) }))
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,186 @@
+package chi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	zhtranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// DefaultLocale 默认的验证错误语言
+const DefaultLocale = "zh"
+
+// FieldError 字段级验证错误
+type FieldError struct {
+	// Field 字段路径，使用json标签名，如address.city、items[0].name
+	Field string `json:"field"`
+	// Rule 验证失败的规则，如required、max
+	Rule string `json:"rule"`
+	// Param 规则参数，如max=32中的32
+	Param string `json:"param,omitempty"`
+	// Message 本地化的错误信息
+	Message string `json:"message"`
+}
+
+// validation 全局验证器状态
+// gin的binding.Validator是进程级的，因此翻译器和自定义规则也是进程级的
+var validation struct {
+	once     sync.Once
+	validate *validator.Validate
+	uni      *ut.UniversalTranslator
+}
+
+// initValidation 初始化验证器的字段名解析和翻译器
+func initValidation() {
+	validation.once.Do(func() {
+		if binding.Validator == nil {
+			return
+		}
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(fieldTagName)
+
+		zhLocale := zh.New()
+		validation.uni = ut.New(zhLocale, zhLocale, en.New())
+		zhTrans, _ := validation.uni.GetTranslator("zh")
+		enTrans, _ := validation.uni.GetTranslator("en")
+		if err := zhtranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+			return
+		}
+		if err := entranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+			return
+		}
+		validation.validate = v
+	})
+}
+
+// fieldTagName 按json、form、uri、header标签顺序解析字段名
+func fieldTagName(field reflect.StructField) string {
+	for _, tag := range [...]string{"json", "form", "uri", "header"} {
+		name := tagName(field.Tag.Get(tag))
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// RegisterValidation 注册自定义验证规则及其错误信息
+// 规则注册到gin的全局验证器，对所有Server生效
+// 参数 tag: 规则名称，在binding标签中使用
+// 参数 fn: 验证函数
+// 参数 messages: 语言到错误信息模板的映射，如{"zh": "{0}必须是合法的手机号"}，{0}为字段名，{1}为规则参数
+// 返回值: error 注册过程中的错误信息
+func (s *Server) RegisterValidation(tag string, fn validator.Func, messages map[string]string) error {
+	initValidation()
+	if validation.validate == nil {
+		return errors.New("binding validator is not a go-playground validator")
+	}
+
+	if err := validation.validate.RegisterValidation(tag, fn); err != nil {
+		return fmt.Errorf("failed to register validation %s: %w", tag, err)
+	}
+
+	for locale, message := range messages {
+		trans, found := validation.uni.GetTranslator(locale)
+		if !found {
+			return fmt.Errorf("unsupported validation locale: %s", locale)
+		}
+		message := message
+		err := validation.validate.RegisterTranslation(tag, trans,
+			func(ut ut.Translator) error {
+				return ut.Add(tag, message, true)
+			},
+			func(ut ut.Translator, fe validator.FieldError) string {
+				t, err := ut.T(fe.Tag(), fe.Field(), fe.Param())
+				if err != nil {
+					return fe.Error()
+				}
+				return t
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register validation message %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// Locale 获取请求的验证错误语言
+// 根据Accept-Language选择第一个支持的语言，不支持时使用DefaultLocale
+// 返回值: string 语言标识，如"zh"、"en"
+func (c *Context) Locale() string {
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		lang, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ = strings.Cut(lang, "-")
+		lang = strings.ToLower(lang)
+		if lang == "zh" || lang == "en" {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
+
+// TranslateValidation 将验证错误转换为本地化的字段级错误列表
+// 参数 ctx: 请求上下文，用于选择语言
+// 参数 err: 绑定或验证返回的错误
+// 返回值: []FieldError 字段错误列表，err不是验证错误时返回nil
+func TranslateValidation(ctx *Context, err error) []FieldError {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	initValidation()
+	var trans ut.Translator
+	if validation.uni != nil {
+		trans, _ = validation.uni.GetTranslator(ctx.Locale())
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		message := fe.Error()
+		if trans != nil {
+			message = fe.Translate(trans)
+		}
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message,
+		})
+	}
+	return fields
+}
+
+// fieldPath 去掉命名空间中的顶层结构体名，如CreateUserReq.address.city转换为address.city
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// bindingError 转换绑定错误
+// 验证错误原样返回，由Res生成字段级错误，其他错误包装为ErrBinding
+func bindingError(err error) error {
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return ErrBinding.Wrap(err)
+}
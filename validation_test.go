@@ -0,0 +1,84 @@
+package chi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validationAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type validationReq struct {
+	Name    string            `json:"name" binding:"required,max=4"`
+	Mobile  string            `json:"mobile" binding:"omitempty,mobile"`
+	Address validationAddress `json:"address"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	s := newTestServer()
+	require.NoError(t, s.RegisterValidation("mobile", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "1") && len(fl.Field().String()) == 11
+	}, map[string]string{
+		"zh": "{0}必须是合法的手机号",
+		"en": "{0} must be a valid mobile number",
+	}))
+
+	s.POST("/users", func(c *Context) {
+		var req validationReq
+		Res(c, c.ShouldBindJSON(&req))
+	})
+
+	fieldErrors := func(t *testing.T, lang string) []interface{} {
+		w := doRequest(s, http.MethodPost, "/users", `{"name":"toolong","mobile":"123"}`, map[string]string{"Accept-Language": lang})
+		body := decodeResponse(t, w)
+		assert.Equal(t, float64(ErrBinding.Code), body["code"])
+		assert.Equal(t, ErrBinding.Message, body["message"])
+		data, ok := body["data"].([]interface{})
+		require.True(t, ok)
+		require.Len(t, data, 3)
+		return data
+	}
+
+	t.Run("Chinese", func(t *testing.T) {
+		data := fieldErrors(t, "zh-CN,zh;q=0.9")
+		assert.Equal(t, map[string]interface{}{
+			"field": "name", "rule": "max", "param": "4", "message": "name长度不能超过4个字符",
+		}, data[0])
+		assert.Equal(t, "mobile必须是合法的手机号", data[1].(map[string]interface{})["message"])
+		assert.Equal(t, "address.city", data[2].(map[string]interface{})["field"])
+	})
+
+	t.Run("English", func(t *testing.T) {
+		data := fieldErrors(t, "en-US,en;q=0.8")
+		assert.Equal(t, "mobile must be a valid mobile number", data[1].(map[string]interface{})["message"])
+		assert.Equal(t, "city is a required field", data[2].(map[string]interface{})["message"])
+	})
+
+	t.Run("UnsupportedLocale", func(t *testing.T) {
+		err := s.RegisterValidation("even", func(fl validator.FieldLevel) bool { return true }, map[string]string{"fr": "{0}"})
+		assert.Error(t, err)
+	})
+}
+
+func TestContextLocale(t *testing.T) {
+	cases := map[string]string{
+		"":                      DefaultLocale,
+		"en":                    "en",
+		"fr-FR,en;q=0.5":        "en",
+		"ja":                    DefaultLocale,
+		"zh-TW;q=0.9, en;q=0.8": "zh",
+	}
+	for header, expected := range cases {
+		s := newTestServer()
+		var locale string
+		s.GET("/", func(c *Context) { locale = c.Locale() })
+		doRequest(s, http.MethodGet, "/", "", map[string]string{"Accept-Language": header})
+		assert.Equal(t, expected, locale, header)
+	}
+}